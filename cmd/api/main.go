@@ -2,26 +2,47 @@ package main
 
 import (
 	"backend/core/config"
+	"backend/core/metrics"
+	"context"
 	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func main() {
 	viperConfig := config.NewViper()
-	log := config.NewLogger(viperConfig)
-	db := config.NewDatabase(viperConfig, log)
-	validate := config.NewValidator(viperConfig)
-	app := config.NewFiber(viperConfig)
+	appConfig := config.NewAppConfig(viperConfig)
+	log := config.NewLogger(appConfig)
+	shutdownTracing := config.NewTracing(appConfig, log)
+	defer shutdownTracing(context.Background())
+	appMetrics := metrics.New()
+	db := config.NewDatabase(appConfig, log, appMetrics)
+	validate := config.NewValidator(appConfig)
+	translator := config.NewTranslator(validate)
+	app := config.NewFiber(appConfig, log, translator)
+	reloader := config.NewConfigReloader(viperConfig, appConfig, log)
+	reloader.Watch()
+
+	var mongoDb *mongo.Database
+	if appConfig.Idempotency.Backend == "mongo" {
+		mongoDb = config.NewMongoDb(appConfig, log)
+	}
+
+	store := config.NewStorage(appConfig, log)
 
 	config.Bootstrap(&config.BootstrapConfig{
 		DB:       db,
 		App:      app,
 		Log:      log,
 		Validate: validate,
-		Config:   viperConfig,
+		Config:   appConfig,
+		Metrics:  appMetrics,
+		Reloader: reloader,
+		Mongo:    mongoDb,
+		Storage:  store,
 	})
 
-	webPort := viperConfig.GetInt("web.port")
-	err := app.Listen(fmt.Sprintf(":%d", webPort))
+	err := app.Listen(fmt.Sprintf(":%d", appConfig.Web.Port))
 	if err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
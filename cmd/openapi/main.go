@@ -0,0 +1,59 @@
+package main
+
+import (
+	"backend/core/openapi"
+	"backend/core/routes"
+	"backend/web/controller"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Mencetak dokumen OpenAPI tanpa perlu database, atau dengan -check
+// gagal (exit 1) jika ada route yang belum punya dokumentasi di routes.Operations
+func main() {
+	check := flag.Bool("check", false, "gagal jika ada route tanpa spec")
+	flag.Parse()
+
+	noop := func(c *fiber.Ctx) error { return c.Next() }
+
+	routeConfig := routes.RouteConfig{
+		AppName:               "api-aestech-panel",
+		DevMode:               true,
+		App:                   fiber.New(),
+		RequestIDMiddleware:   noop,
+		TracingMiddleware:     noop,
+		LogMiddleware:         noop,
+		IdempotencyMiddleware: noop,
+		GuestRateLimit:        noop,
+		AuthRateLimit:         noop,
+		AuthMiddleware:        noop,
+		MetricsHandler:        noop,
+		BranchsController:     &controller.BranchsController{},
+		WebhookController:     &controller.WebhookController{},
+	}
+	routeConfig.Setup()
+
+	if *check {
+		missing := openapi.Missing(routeConfig.App.GetRoutes(true), routeConfig.Operations(), routes.OpenAPIPath, routes.DocsPath)
+		if len(missing) > 0 {
+			fmt.Fprintln(os.Stderr, "route tanpa spec OpenAPI:")
+			for _, route := range missing {
+				fmt.Fprintln(os.Stderr, " - "+route)
+			}
+			os.Exit(1)
+		}
+		fmt.Println("semua route punya spec OpenAPI")
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(routeConfig.OpenAPI()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
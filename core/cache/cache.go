@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// Hasil lookup cache untuk metrics
+const (
+	ResultHit       = "hit"
+	ResultRemoteHit = "remote_hit"
+	ResultMiss      = "miss"
+)
+
+// Config mengatur Cache. RemoteTTL dipakai untuk Remote, TTL untuk LRU lokal.
+// Dengan beberapa proses, invalidasi hanya pasti sampai ke Remote, jadi TTL lokal
+// sebaiknya pendek karena itu batas lama data basi di proses lain
+type Config struct {
+	Capacity  int
+	TTL       time.Duration
+	RemoteTTL time.Duration
+}
+
+// Cache adalah read-through cache dua lapis: LRU lokal lalu Remote (opsional), dan
+// loader (database) jika keduanya miss. Miss bersamaan untuk key yang sama hanya
+// memanggil loader sekali (singleflight)
+type Cache[V any] struct {
+	Name   string
+	Remote Remote
+	Log    *logrus.Logger
+	// Observe dipanggil dengan salah satu Result* untuk setiap lookup. Pemanggil yang ikut
+	// menunggu singleflight dihitung dengan hasil yang sama seperti pemanggil pertama. Boleh nil
+	Observe func(name, result string)
+
+	config Config
+	local  *LRU[V]
+	group  singleflight.Group
+	// generation naik setiap Invalidate, hasil load yang dimulai sebelumnya tidak disimpan
+	generation atomic.Uint64
+}
+
+func New[V any](name string, config Config, remote Remote, log *logrus.Logger) *Cache[V] {
+	return &Cache[V]{
+		Name:   name,
+		Remote: remote,
+		Log:    log,
+		config: config,
+		local:  NewLRU[V](config.Capacity, config.TTL),
+	}
+}
+
+// Get mengembalikan nilai key dari cache, atau dari load lalu disimpan ke cache.
+// Error load tidak di-cache. Remote yang error dianggap miss supaya Redis mati tidak
+// membuat request gagal
+func (c *Cache[V]) Get(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	if value, ok := c.local.Get(key); ok {
+		c.observe(ResultHit)
+		return value, nil
+	}
+
+	shared, err, _ := c.group.Do(key, func() (interface{}, error) {
+		if value, ok := c.getRemote(ctx, key); ok {
+			c.local.Set(key, value)
+			return loaded[V]{value: value, result: ResultRemoteHit}, nil
+		}
+
+		generation := c.generation.Load()
+		// pemanggil lain ikut menunggu load ini, jangan gagal hanya karena request pertama dibatalkan
+		value, err := load(context.WithoutCancel(ctx))
+		if err != nil {
+			return loaded[V]{value: value, result: ResultMiss}, err
+		}
+		if c.generation.Load() == generation {
+			c.local.Set(key, value)
+			c.setRemote(ctx, key, value)
+		}
+		return loaded[V]{value: value, result: ResultMiss}, nil
+	})
+
+	result := shared.(loaded[V])
+	c.observe(result.result)
+	return result.value, err
+}
+
+// loaded adalah hasil singleflight, result dibagi ke semua pemanggil yang menunggu
+type loaded[V any] struct {
+	value  V
+	result string
+}
+
+// Invalidate menghapus key dari LRU lokal dan Remote
+func (c *Cache[V]) Invalidate(ctx context.Context, keys ...string) {
+	c.generation.Add(1)
+	for _, key := range keys {
+		c.local.Delete(key)
+		// load yang sedang berjalan bisa membawa data lama, jangan dibagi ke pemanggil berikutnya
+		c.group.Forget(key)
+	}
+	if c.Remote == nil {
+		return
+	}
+	if err := c.Remote.Delete(ctx, c.remoteKeys(keys)...); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed to invalidate cache %s : %+v", c.Name, err)
+	}
+}
+
+func (c *Cache[V]) getRemote(ctx context.Context, key string) (V, bool) {
+	var value V
+	if c.Remote == nil {
+		return value, false
+	}
+
+	raw, ok, err := c.Remote.Get(ctx, c.Name+":"+key)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed to read cache %s : %+v", c.Name, err)
+		return value, false
+	}
+	if !ok {
+		return value, false
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed to decode cache %s : %+v", c.Name, err)
+		return value, false
+	}
+	return value, true
+}
+
+func (c *Cache[V]) setRemote(ctx context.Context, key string, value V) {
+	if c.Remote == nil {
+		return
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed to encode cache %s : %+v", c.Name, err)
+		return
+	}
+	if err := c.Remote.Set(ctx, c.Name+":"+key, raw, c.config.RemoteTTL); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed to write cache %s : %+v", c.Name, err)
+	}
+}
+
+func (c *Cache[V]) remoteKeys(keys []string) []string {
+	remoteKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		remoteKeys = append(remoteKeys, c.Name+":"+key)
+	}
+	return remoteKeys
+}
+
+func (c *Cache[V]) observe(result string) {
+	if c.Observe != nil {
+		c.Observe(c.Name, result)
+	}
+}
@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLRUEviction(t *testing.T) {
+	lru := NewLRU[string](2, time.Minute)
+	lru.Set("a", "1")
+	lru.Set("b", "2")
+
+	// a baru dipakai, jadi b yang paling lama tidak dipakai dan dibuang
+	if _, ok := lru.Get("a"); !ok {
+		t.Fatal("a missing")
+	}
+	lru.Set("c", "3")
+
+	if _, ok := lru.Get("b"); ok {
+		t.Fatal("b not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := lru.Get(key); !ok {
+			t.Fatalf("%s evicted", key)
+		}
+	}
+
+	// Set key yang sudah ada mengganti nilai tanpa menambah entry
+	lru.Set("a", "10")
+	if value, _ := lru.Get("a"); value != "10" || lru.Len() != 2 {
+		t.Fatalf("a = %q len %d, want 10 and 2", value, lru.Len())
+	}
+}
+
+func TestLRUTTL(t *testing.T) {
+	lru := NewLRU[string](10, 10*time.Millisecond)
+	lru.Set("a", "1")
+	if _, ok := lru.Get("a"); !ok {
+		t.Fatal("a missing before ttl")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := lru.Get("a"); ok {
+		t.Fatal("a still cached after ttl")
+	}
+	if lru.Len() != 0 {
+		t.Fatalf("len %d, want expired entry removed", lru.Len())
+	}
+}
+
+type observed struct {
+	mu      sync.Mutex
+	results map[string]int
+}
+
+func (o *observed) observe(name, result string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results[result]++
+}
+
+func (o *observed) count(result string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.results[result]
+}
+
+func newTestCache(remote Remote) (*Cache[string], *observed) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	o := &observed{results: map[string]int{}}
+	c := New[string]("branch", Config{Capacity: 10, TTL: time.Minute, RemoteTTL: time.Minute}, remote, log)
+	c.Observe = o.observe
+	return c, o
+}
+
+func TestCacheReadThrough(t *testing.T) {
+	remote := NewFakeRemote()
+	c, o := newTestCache(remote)
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	load := func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		return "value", nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if value, err := c.Get(ctx, "A1", load); err != nil || value != "value" {
+			t.Fatalf("Get %d = %q, %v", i+1, value, err)
+		}
+	}
+	if loads.Load() != 1 || o.count(ResultMiss) != 1 || o.count(ResultHit) != 1 {
+		t.Fatalf("loads %d, results %v; want 1 load, 1 miss, 1 hit", loads.Load(), o.results)
+	}
+
+	// proses lain dengan LRU kosong membaca dari Remote
+	other, otherObserved := newTestCache(remote)
+	if value, err := other.Get(ctx, "A1", load); err != nil || value != "value" {
+		t.Fatalf("other Get = %q, %v", value, err)
+	}
+	if loads.Load() != 1 || otherObserved.count(ResultRemoteHit) != 1 {
+		t.Fatalf("loads %d, results %v; want remote hit", loads.Load(), otherObserved.results)
+	}
+}
+
+func TestCacheLoadErrorNotCached(t *testing.T) {
+	c, _ := newTestCache(nil)
+	ctx := context.Background()
+	failed := errors.New("db down")
+
+	if _, err := c.Get(ctx, "A1", func(ctx context.Context) (string, error) { return "", failed }); !errors.Is(err, failed) {
+		t.Fatalf("Get: %v, want db down", err)
+	}
+	value, err := c.Get(ctx, "A1", func(ctx context.Context) (string, error) { return "value", nil })
+	if err != nil || value != "value" {
+		t.Fatalf("Get after error = %q, %v; want loaded again", value, err)
+	}
+}
+
+func TestCacheSingleflightObserve(t *testing.T) {
+	c, o := newTestCache(nil)
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	const callers = 5
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, err := c.Get(ctx, "A1", load); err != nil || value != "value" {
+				t.Errorf("Get = %q, %v", value, err)
+			}
+		}()
+	}
+	// beri waktu semua pemanggil bergabung ke singleflight sebelum load selesai
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// load hanya sekali, tapi setiap pemanggil tercatat sebagai miss
+	if loads.Load() != 1 || o.count(ResultMiss) != callers {
+		t.Fatalf("loads %d, misses %d; want 1 load and %d misses", loads.Load(), o.count(ResultMiss), callers)
+	}
+}
+
+func TestCacheInvalidateDuringLoad(t *testing.T) {
+	remote := NewFakeRemote()
+	c, _ := newTestCache(remote)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	staleDone := make(chan string)
+	go func() {
+		value, _ := c.Get(ctx, "A1", func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "old", nil
+		})
+		staleDone <- value
+	}()
+	<-started
+
+	// data berubah saat load lama masih berjalan
+	c.Invalidate(ctx, "A1")
+
+	// Forget: pemanggil berikutnya tidak ikut menunggu load lama, tapi load sendiri
+	value, err := c.Get(ctx, "A1", func(ctx context.Context) (string, error) { return "new", nil })
+	if err != nil || value != "new" {
+		t.Fatalf("Get after invalidate = %q, %v; want new", value, err)
+	}
+
+	close(release)
+	if stale := <-staleDone; stale != "old" {
+		t.Fatalf("stale caller got %q, want old", stale)
+	}
+
+	// hasil load lama tidak menimpa cache lokal maupun Remote
+	value, err = c.Get(ctx, "A1", func(ctx context.Context) (string, error) {
+		t.Fatal("load called, want cached new")
+		return "", nil
+	})
+	if err != nil || value != "new" {
+		t.Fatalf("Get = %q, %v; want new", value, err)
+	}
+	if raw, ok, _ := remote.Get(ctx, "branch:A1"); !ok || string(raw) != `"new"` {
+		t.Fatalf("remote = %s %v, want new", raw, ok)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	remote := NewFakeRemote()
+	c, o := newTestCache(remote)
+	ctx := context.Background()
+
+	load := func(ctx context.Context) (string, error) { return "value", nil }
+	if _, err := c.Get(ctx, "A1", load); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Invalidate(ctx, "A1")
+	if _, ok, _ := remote.Get(ctx, "branch:A1"); ok {
+		t.Fatal("remote still has A1 after invalidate")
+	}
+	if _, err := c.Get(ctx, "A1", load); err != nil {
+		t.Fatal(err)
+	}
+	if o.count(ResultMiss) != 2 {
+		t.Fatalf("misses %d, want 2", o.count(ResultMiss))
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU adalah cache in-memory dengan kapasitas tetap dan TTL per entry.
+// Entry yang paling lama tidak dipakai dibuang saat kapasitas penuh
+type LRU[V any] struct {
+	capacity int
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	items   map[string]*list.Element
+	entries *list.List
+}
+
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+func NewLRU[V any](capacity int, ttl time.Duration) *LRU[V] {
+	return &LRU[V]{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    map[string]*list.Element{},
+		entries:  list.New(),
+	}
+}
+
+func (l *LRU[V]) Get(key string) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var zero V
+	element, ok := l.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := element.Value.(*lruEntry[V])
+	if time.Now().After(entry.expiresAt) {
+		l.remove(element)
+		return zero, false
+	}
+
+	l.entries.MoveToFront(element)
+	return entry.value, true
+}
+
+func (l *LRU[V]) Set(key string, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := time.Now().Add(l.ttl)
+	if element, ok := l.items[key]; ok {
+		entry := element.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.entries.MoveToFront(element)
+		return
+	}
+
+	l.items[key] = l.entries.PushFront(&lruEntry[V]{key: key, value: value, expiresAt: expiresAt})
+	for l.entries.Len() > l.capacity {
+		l.remove(l.entries.Back())
+	}
+}
+
+func (l *LRU[V]) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		l.remove(element)
+	}
+}
+
+// Len mengembalikan jumlah entry, termasuk yang sudah expired tapi belum dibuang
+func (l *LRU[V]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.entries.Len()
+}
+
+func (l *LRU[V]) remove(element *list.Element) {
+	l.entries.Remove(element)
+	delete(l.items, element.Value.(*lruEntry[V]).key)
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig adalah koneksi Redis untuk RedisRemote
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db" validate:"min=0"`
+	// Prefix ditambahkan ke setiap key supaya aman berbagi Redis dengan aplikasi lain
+	Prefix string `mapstructure:"prefix"`
+}
+
+// RedisRemote menyimpan cache di Redis sehingga bisa dipakai bersama oleh beberapa proses
+type RedisRemote struct {
+	Client *redis.Client
+	Prefix string
+}
+
+func NewRedisRemote(config RedisConfig) *RedisRemote {
+	return &RedisRemote{
+		Client: redis.NewClient(&redis.Options{
+			Addr:     config.Addr,
+			Password: config.Password,
+			DB:       config.DB,
+		}),
+		Prefix: config.Prefix,
+	}
+}
+
+func (r *RedisRemote) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.Client.Get(ctx, r.Prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (r *RedisRemote) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.Client.Set(ctx, r.Prefix+key, value, ttl).Err()
+}
+
+func (r *RedisRemote) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, r.Prefix+key)
+	}
+	return r.Client.Del(ctx, prefixed...).Err()
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Remote adalah cache bersama antar proses (Redis), dipakai sebagai lapis kedua setelah LRU
+type Remote interface {
+	// Get mengembalikan nilai dan false jika key tidak ada
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// FakeRemote adalah Remote in-memory untuk development dan test tanpa server Redis
+type FakeRemote struct {
+	mu    sync.Mutex
+	items map[string]fakeItem
+}
+
+type fakeItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewFakeRemote() *FakeRemote {
+	return &FakeRemote{items: map[string]fakeItem{}}
+}
+
+func (f *FakeRemote) Get(_ context.Context, key string) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[key]
+	if !ok || time.Now().After(item.expiresAt) {
+		delete(f.items, key)
+		return nil, false, nil
+	}
+	return append([]byte(nil), item.value...), true, nil
+}
+
+func (f *FakeRemote) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.items[key] = fakeItem{value: append([]byte(nil), value...), expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (f *FakeRemote) Delete(_ context.Context, keys ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, key := range keys {
+		delete(f.items, key)
+	}
+	return nil
+}
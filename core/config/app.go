@@ -1,17 +1,31 @@
 package config
 
 import (
+	"backend/core/cache"
+	"backend/core/idempotency"
+	"backend/core/metrics"
 	"backend/core/middlewares"
+	"backend/core/outbox"
+	"backend/core/ratelimit"
+	"backend/core/repo"
 	"backend/core/routes"
+	"backend/core/storage"
+	"backend/core/utils"
+	"backend/core/webhook"
 	"backend/web/controller"
+	"backend/web/entity"
+	"backend/web/model"
 	"backend/web/repository"
 	"backend/web/service"
+	"context"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
 )
 
@@ -20,44 +34,186 @@ type BootstrapConfig struct {
 	App      *fiber.App
 	Log      *logrus.Logger
 	Validate *validator.Validate
-	Config   *viper.Viper
+	Config   *AppConfig
+	Metrics  *metrics.Metrics
+	Reloader *ConfigReloader
+	Mongo    *mongo.Database
+	Storage  storage.Storage
 }
 
 func Bootstrap(config *BootstrapConfig) {
 
-	appMode := config.Config.GetString("app.development")
-
-	if appMode == "dev" {
+	if config.Config.App.Development == "dev" {
 		config.App.Use(cors.New(cors.Config{
 			AllowOrigins:  "*",
-			AllowMethods:  "GET,POST,PUT,DELETE,OPTIONS",
-			AllowHeaders:  "Origin, Content-Type, Accept, Authorization",
-			ExposeHeaders: "Content-Length",
+			AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+			AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-Request-ID, Idempotency-Key, If-Match, If-None-Match",
+			ExposeHeaders: "Content-Length, X-Request-ID, ETag, Idempotent-Replayed, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After",
 			// AllowCredentials: true,
 		}))
 	} else {
 		config.App.Use(cors.New(cors.Config{
-			AllowOrigins:  config.Config.GetString("allowedWeb"),
-			AllowMethods:  "GET,POST,PUT,DELETE,OPTIONS",
-			AllowHeaders:  "Origin, Content-Type, Accept, Authorization",
-			ExposeHeaders: "Content-Length",
+			// dibaca tiap request supaya perubahan allowedWeb langsung berlaku tanpa restart
+			AllowOriginsFunc: func(origin string) bool {
+				return isAllowedOrigin(config.Reloader.Current().AllowedWeb, origin)
+			},
+			AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+			AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-Request-ID, Idempotency-Key, If-Match, If-None-Match",
+			ExposeHeaders: "Content-Length, X-Request-ID, ETag, Idempotent-Replayed, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After",
 			// AllowCredentials: true,
 		}))
 	}
 
-	logMiddleware := middlewares.RequestLogger(config.Log)
+	config.Reloader.OnChange(func(old, new *AppConfig) {
+		if old.Log.Level != new.Log.Level {
+			config.Log.SetLevel(parseLogLevel(new.Log.Level))
+		}
+	})
+
+	logMiddleware := middlewares.RequestLogger(config.Log, config.Metrics)
+
+	idempotencyStore := newIdempotencyStore(config)
+	idempotency.StartPurge(idempotencyStore, time.Hour, config.Log)
+	idempotencyMiddleware := middlewares.Idempotency(idempotencyStore,
+		time.Duration(config.Config.Idempotency.TTL)*time.Second,
+		time.Duration(config.Config.Idempotency.Lease)*time.Second,
+		config.Log)
+
+	rateLimitStore := newRateLimitStore(config)
+	ratelimit.StartPurge(rateLimitStore, 10*time.Minute, config.Log)
+	guestRateLimit := middlewares.RateLimit(rateLimitStore, "guest", rateLimitRule(config.Reloader, func(c RateLimitConfig) ratelimit.Rule {
+		return c.Guest
+	}), config.Log)
+	authRateLimit := middlewares.RateLimit(rateLimitStore, "auth", rateLimitRule(config.Reloader, func(c RateLimitConfig) ratelimit.Rule {
+		return c.Auth
+	}), config.Log)
 
 	branchRepository := repository.NewBranchsRepository(config.Log)
 
-	branchService := service.NewBrandsService(config.DB, config.Log, config.Validate, branchRepository)
+	config.Metrics.Registry.MustRegister(metrics.NewBusinessCollector(config.DB, config.Log, branchRepository))
+
+	provisioningDefaults := func() model.ProvisioningDefaults {
+		return config.Reloader.Current().Provisioning
+	}
+
+	uploadConfig := func() utils.UploadConfig {
+		return config.Reloader.Current().Upload.UploadConfig()
+	}
+
+	unitOfWork := repo.NewUnitOfWork(config.DB, config.Config.Database.Transaction.TxOptions())
+
+	branchCache := repository.NewBranchCache(newBranchCache(config), branchRepository, config.Log)
+
+	branchService := service.NewBrandsService(config.DB, config.Log, config.Validate, provisioningDefaults, uploadConfig, config.Storage, unitOfWork, branchCache, branchRepository, repository.NewBranchStatsSnapshotRepository(config.Log))
+
+	webhookSubscriptionRepository := repository.NewWebhookSubscriptionRepository(config.Log)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(config.Log)
+	webhookService := service.NewWebhookService(config.DB, config.Log, config.Validate, webhook.NewSender(time.Duration(config.Config.Webhook.Timeout)*time.Second), config.Config.Webhook.WebhookOptions(), webhookSubscriptionRepository, webhookDeliveryRepository)
+	service.StartWebhookDispatcher(context.Background(), webhookService, time.Duration(config.Config.Webhook.Interval)*time.Second)
+
+	// domain event dari outbox dikirim ke handler setelah transaksi commit
+	relay := outbox.NewRelay(config.DB, config.Log, config.Config.Outbox.RelayConfig())
+	relay.Subscribe(outbox.AllEvents, outbox.LogHandler(config.Log))
+	relay.Subscribe(outbox.AllEvents, webhookService.HandleEvent)
+	relay.Subscribe(outbox.AllEvents, branchCache.HandleEvent)
+	relay.Start(context.Background())
+	outbox.StartPurge(relay, time.Hour, time.Duration(config.Config.Outbox.Retention)*time.Hour, config.Log)
+	expiredCheck := service.StartExpiredCheck(branchService, time.Duration(config.Config.Jobs.ExpiredCheckInterval)*time.Second)
+	config.Reloader.OnChange(func(old, new *AppConfig) {
+		if old.Jobs.ExpiredCheckInterval != new.Jobs.ExpiredCheckInterval {
+			expiredCheck.Reset(time.Duration(new.Jobs.ExpiredCheckInterval) * time.Second)
+		}
+	})
 
 	branchController := controller.NewBrandsController(branchService, config.Log)
+	webhookController := controller.NewWebhookController(webhookService, config.Log)
 
 	routeConfig := routes.RouteConfig{
-		App:               config.App,
-		LogMiddleware:     logMiddleware,
-		BranchsController: branchController,
+		AppName:               config.Config.App.Name,
+		DevMode:               config.Config.App.Development == "dev",
+		App:                   config.App,
+		RequestIDMiddleware:   middlewares.RequestID(),
+		TracingMiddleware:     middlewares.Tracing(),
+		LogMiddleware:         logMiddleware,
+		IdempotencyMiddleware: idempotencyMiddleware,
+		GuestRateLimit:        guestRateLimit,
+		AuthRateLimit:         authRateLimit,
+		AuthMiddleware:        middlewares.NewAuth(utils.NewTokenUtil(config.Config.SecretKey)),
+		MetricsHandler:        config.Metrics.Handler(),
+		BranchsController:     branchController,
+		WebhookController:     webhookController,
 	}
 
 	routeConfig.Setup()
 }
+
+// newIdempotencyStore memilih backend penyimpanan Idempotency-Key sesuai config
+func newIdempotencyStore(config *BootstrapConfig) idempotency.Store {
+	if config.Config.Idempotency.Backend == "mongo" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		store, err := idempotency.NewMongoStore(ctx, config.Mongo)
+		if err != nil {
+			config.Log.Fatalf("failed to create idempotency store: %v", err)
+		}
+		return store
+	}
+
+	return idempotency.NewMySQLStore(config.DB)
+}
+
+// newBranchCache membuat cache lookup cabang sesuai config, nil jika cache dimatikan
+func newBranchCache(config *BootstrapConfig) *cache.Cache[entity.Branch] {
+	cacheConfig := config.Config.Cache
+	if !cacheConfig.Enabled {
+		return nil
+	}
+
+	var remote cache.Remote
+	switch cacheConfig.Remote {
+	case "redis":
+		remote = cache.NewRedisRemote(cacheConfig.Redis)
+	case "fake":
+		remote = cache.NewFakeRemote()
+	}
+
+	branchCache := cache.New[entity.Branch]("branch", cacheConfig.CacheConfig(), remote, config.Log)
+	branchCache.Observe = config.Metrics.ObserveCache
+	return branchCache
+}
+
+// newRateLimitStore memilih backend rate limit sesuai config
+func newRateLimitStore(config *BootstrapConfig) ratelimit.Store {
+	if config.Config.RateLimit.Backend == "mysql" {
+		return ratelimit.NewMySQLStore(config.DB)
+	}
+
+	if config.Config.Web.Prefork {
+		config.Log.Warn("Rate limit backend memory is not shared between prefork processes, use backend mysql")
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+// rateLimitRule membaca rule terbaru dari config setiap request,
+// rule kosong (tanpa batas) jika rate limit dimatikan
+func rateLimitRule(reloader *ConfigReloader, pick func(RateLimitConfig) ratelimit.Rule) func() ratelimit.Rule {
+	return func() ratelimit.Rule {
+		current := reloader.Current().RateLimit
+		if !current.Enabled {
+			return ratelimit.Rule{}
+		}
+		return pick(current)
+	}
+}
+
+// isAllowedOrigin mencocokkan origin dengan daftar allowedWeb (dipisah koma)
+func isAllowedOrigin(allowedWeb, origin string) bool {
+	for _, allowed := range strings.Split(allowedWeb, ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"backend/core/cache"
+	"backend/core/outbox"
+	"backend/core/ratelimit"
+	"backend/core/repo"
+	"backend/core/utils"
+	"backend/web/model"
+	"backend/web/service"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+// AppConfig adalah bentuk typed dari config.json, dibaca sekali saat startup
+// lalu dioper ke semua constructor sebagai pengganti *viper.Viper
+type AppConfig struct {
+	App          AppSettings                `mapstructure:"app"`
+	SecretKey    string                     `mapstructure:"secret_key" validate:"required"`
+	Web          WebConfig                  `mapstructure:"web"`
+	Log          LogConfig                  `mapstructure:"log"`
+	AllowedWeb   string                     `mapstructure:"allowedWeb" validate:"required_unless=App.Development dev"`
+	Provisioning model.ProvisioningDefaults `mapstructure:"provisioning"`
+	Jobs         JobsConfig                 `mapstructure:"jobs"`
+	Idempotency  IdempotencyConfig          `mapstructure:"idempotency"`
+	Outbox       OutboxConfig               `mapstructure:"outbox"`
+	Webhook      WebhookConfig              `mapstructure:"webhook"`
+	Cache        CacheConfig                `mapstructure:"cache"`
+	RateLimit    RateLimitConfig            `mapstructure:"rateLimit"`
+	Upload       UploadConfig               `mapstructure:"upload"`
+	Tracing      TracingConfig              `mapstructure:"tracing"`
+	Database     DatabaseConfig             `mapstructure:"database"`
+	Mongo        MongoConfig                `mapstructure:"mongo"`
+}
+
+type AppSettings struct {
+	Name        string `mapstructure:"name" validate:"required"`
+	Development string `mapstructure:"development" validate:"required,oneof=dev staging production"`
+}
+
+type WebConfig struct {
+	Prefork bool `mapstructure:"prefork"`
+	Port    int  `mapstructure:"port" validate:"required,min=1,max=65535"`
+}
+
+type LogConfig struct {
+	Level  string `mapstructure:"level" validate:"omitempty,oneof=panic fatal error warn warning info debug trace 0 1 2 3 4 5 6"`
+	Format string `mapstructure:"format" validate:"omitempty,oneof=json text"`
+	Output string `mapstructure:"output"`
+}
+
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter" validate:"omitempty,oneof=stdout otlp"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sampleRatio" validate:"gte=0,lte=1"`
+}
+
+// JobsConfig mengatur job berkala, waktu dalam detik. Bisa diubah tanpa restart
+type JobsConfig struct {
+	ExpiredCheckInterval int `mapstructure:"expiredCheckInterval" validate:"required,min=60"`
+}
+
+type IdempotencyConfig struct {
+	Backend string `mapstructure:"backend" validate:"required,oneof=mysql mongo"`
+	// TTL dalam detik
+	TTL int `mapstructure:"ttl" validate:"required,min=1"`
+	// Lease adalah lama request yang masih diproses memegang key (detik), dibuat lebih
+	// lama dari request paling lambat. Lewat dari itu retry boleh mengambil alih key
+	Lease int `mapstructure:"lease" validate:"required,min=1,ltefield=TTL"`
+}
+
+// OutboxConfig mengatur relay domain event (outbox.Relay), waktu dalam detik
+type OutboxConfig struct {
+	Interval      int `mapstructure:"interval" validate:"required,min=1"`
+	BatchSize     int `mapstructure:"batchSize" validate:"required,min=1,max=1000"`
+	MaxAttempts   int `mapstructure:"maxAttempts" validate:"required,min=1"`
+	RetryDelay    int `mapstructure:"retryDelay" validate:"required,min=1"`
+	MaxRetryDelay int `mapstructure:"maxRetryDelay" validate:"required,gtefield=RetryDelay"`
+	// Retention adalah lama event terkirim disimpan sebelum dihapus, dalam jam
+	Retention int `mapstructure:"retention" validate:"required,min=1"`
+}
+
+// RelayConfig mengubah section outbox menjadi opsi outbox.Relay
+func (c OutboxConfig) RelayConfig() outbox.RelayConfig {
+	return outbox.RelayConfig{
+		Interval:      time.Duration(c.Interval) * time.Second,
+		BatchSize:     c.BatchSize,
+		MaxAttempts:   c.MaxAttempts,
+		RetryDelay:    time.Duration(c.RetryDelay) * time.Second,
+		MaxRetryDelay: time.Duration(c.MaxRetryDelay) * time.Second,
+		Lease:         time.Minute,
+	}
+}
+
+// WebhookConfig mengatur pengiriman webhook, waktu dalam detik
+type WebhookConfig struct {
+	Interval      int `mapstructure:"interval" validate:"required,min=1"`
+	BatchSize     int `mapstructure:"batchSize" validate:"required,min=1,max=1000"`
+	MaxAttempts   int `mapstructure:"maxAttempts" validate:"required,min=1"`
+	RetryDelay    int `mapstructure:"retryDelay" validate:"required,min=1"`
+	MaxRetryDelay int `mapstructure:"maxRetryDelay" validate:"required,gtefield=RetryDelay"`
+	// Timeout request HTTP ke penerima webhook
+	Timeout int `mapstructure:"timeout" validate:"required,min=1,max=60"`
+}
+
+// WebhookOptions mengubah section webhook menjadi opsi service.WebhookService
+func (c WebhookConfig) WebhookOptions() service.WebhookOptions {
+	return service.WebhookOptions{
+		BatchSize:     c.BatchSize,
+		MaxAttempts:   c.MaxAttempts,
+		RetryDelay:    time.Duration(c.RetryDelay) * time.Second,
+		MaxRetryDelay: time.Duration(c.MaxRetryDelay) * time.Second,
+		// lease lebih lama dari total timeout satu batch
+		Lease: time.Duration(c.Timeout*c.BatchSize)*time.Second + time.Minute,
+	}
+}
+
+// CacheConfig mengatur cache lookup cabang, waktu dalam detik. Remote "redis" berbagi
+// cache antar proses, "fake" adalah Redis tiruan in-memory untuk development
+type CacheConfig struct {
+	Enabled   bool              `mapstructure:"enabled"`
+	Capacity  int               `mapstructure:"capacity" validate:"required_if=Enabled true,omitempty,min=1"`
+	TTL       int               `mapstructure:"ttl" validate:"required_if=Enabled true,omitempty,min=1"`
+	Remote    string            `mapstructure:"remote" validate:"omitempty,oneof=redis fake"`
+	RemoteTTL int               `mapstructure:"remoteTtl" validate:"required_with=Remote,omitempty,min=1"`
+	Redis     cache.RedisConfig `mapstructure:"redis"`
+}
+
+// CacheConfig mengubah section cache menjadi opsi cache.Cache
+func (c CacheConfig) CacheConfig() cache.Config {
+	return cache.Config{
+		Capacity:  c.Capacity,
+		TTL:       time.Duration(c.TTL) * time.Second,
+		RemoteTTL: time.Duration(c.RemoteTTL) * time.Second,
+	}
+}
+
+// validateCacheConfig mewajibkan cache.redis.addr saat remote redis
+func validateCacheConfig(sl validator.StructLevel) {
+	config := sl.Current().Interface().(CacheConfig)
+	if config.Remote == "redis" && config.Redis.Addr == "" {
+		sl.ReportError(config.Redis.Addr, "redis.addr", "redis.addr", "required", "")
+	}
+}
+
+// RateLimitConfig mengatur batas request per route group. Semua nilai kecuali backend
+// bisa diubah tanpa restart
+type RateLimitConfig struct {
+	Enabled bool           `mapstructure:"enabled"`
+	Backend string         `mapstructure:"backend" validate:"required,oneof=memory mysql"`
+	Guest   ratelimit.Rule `mapstructure:"guest"`
+	// Auth berlaku untuk /auth/* (OTP, login), dibuat lebih ketat dari Guest
+	Auth ratelimit.Rule `mapstructure:"auth"`
+	// OtpLockout dipakai ratelimit.Guard untuk mengunci nomor telepon setelah OTP salah berulang kali
+	OtpLockout ratelimit.LockoutPolicy `mapstructure:"otpLockout"`
+}
+
+type UploadConfig struct {
+	Driver string `mapstructure:"driver" validate:"required,oneof=local s3"`
+	// Dir adalah folder lokal yang disajikan di /uploads (driver local)
+	Dir string `mapstructure:"dir" validate:"required_if=Driver local"`
+	// BaseURL adalah prefix URL file driver local, kosong berarti /uploads di host request
+	BaseURL       string   `mapstructure:"baseUrl" validate:"omitempty,url"`
+	MaxFileSizeMB int64    `mapstructure:"maxFileSizeMb" validate:"required,min=1"`
+	AllowedExts   []string `mapstructure:"allowedExts" validate:"required,min=1"`
+	// ThumbnailSize adalah sisi terpanjang thumbnail logo dalam pixel, 0 berarti tanpa thumbnail
+	ThumbnailSize int            `mapstructure:"thumbnailSize" validate:"min=0"`
+	S3            S3UploadConfig `mapstructure:"s3"`
+}
+
+type S3UploadConfig struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"accessKey"`
+	SecretKey string `mapstructure:"secretKey"`
+	UseSSL    bool   `mapstructure:"useSsl"`
+	PublicURL string `mapstructure:"publicUrl" validate:"omitempty,url"`
+}
+
+// UploadConfig mengubah section upload menjadi aturan untuk utils.UploadFiles
+func (c UploadConfig) UploadConfig() utils.UploadConfig {
+	return utils.UploadConfig{
+		AllowedExts:   c.AllowedExts,
+		MaxFileSizeMB: c.MaxFileSizeMB,
+		ThumbnailSize: c.ThumbnailSize,
+	}
+}
+
+// validateUploadConfig mewajibkan section upload.s3 saat driver s3
+func validateUploadConfig(sl validator.StructLevel) {
+	upload := sl.Current().Interface().(UploadConfig)
+	if upload.Driver != "s3" {
+		return
+	}
+
+	required := []struct{ name, value string }{
+		{"s3.endpoint", upload.S3.Endpoint},
+		{"s3.bucket", upload.S3.Bucket},
+		{"s3.accessKey", upload.S3.AccessKey},
+		{"s3.secretKey", upload.S3.SecretKey},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			sl.ReportError(field.value, field.name, field.name, "required", "")
+		}
+	}
+}
+
+type DatabaseConfig struct {
+	Username    string            `mapstructure:"username" validate:"required"`
+	Password    string            `mapstructure:"password"`
+	Host        string            `mapstructure:"host" validate:"required"`
+	Port        int               `mapstructure:"port" validate:"required,min=1,max=65535"`
+	Name        string            `mapstructure:"name" validate:"required"`
+	Pool        PoolConfig        `mapstructure:"pool"`
+	Transaction TransactionConfig `mapstructure:"transaction"`
+}
+
+// TransactionConfig dipakai repo.UnitOfWork. Isolation kosong berarti default MySQL
+// (REPEATABLE READ), retry hanya untuk deadlock dan lock wait timeout
+type TransactionConfig struct {
+	Isolation    string `mapstructure:"isolation" validate:"omitempty,oneof=read_uncommitted read_committed repeatable_read serializable"`
+	MaxRetries   int    `mapstructure:"maxRetries" validate:"min=0,max=10"`
+	RetryDelayMs int    `mapstructure:"retryDelayMs" validate:"min=0"`
+}
+
+// TxOptions mengubah section database.transaction menjadi opsi repo.UnitOfWork
+func (c TransactionConfig) TxOptions() repo.TxOptions {
+	isolation := map[string]sql.IsolationLevel{
+		"read_uncommitted": sql.LevelReadUncommitted,
+		"read_committed":   sql.LevelReadCommitted,
+		"repeatable_read":  sql.LevelRepeatableRead,
+		"serializable":     sql.LevelSerializable,
+	}
+
+	return repo.TxOptions{
+		Isolation:  isolation[c.Isolation],
+		MaxRetries: c.MaxRetries,
+		RetryDelay: time.Duration(c.RetryDelayMs) * time.Millisecond,
+	}
+}
+
+type MongoConfig struct {
+	URL      string     `mapstructure:"url"`
+	Username string     `mapstructure:"username"`
+	Password string     `mapstructure:"password"`
+	Pool     PoolConfig `mapstructure:"pool"`
+}
+
+type PoolConfig struct {
+	Idle     int `mapstructure:"idle" validate:"min=0"`
+	Max      int `mapstructure:"max" validate:"min=0"`
+	Lifetime int `mapstructure:"lifetime" validate:"min=0"`
+}
+
+// NewAppConfig membaca viper ke AppConfig dan menghentikan aplikasi dengan
+// daftar semua key yang hilang atau tidak valid
+func NewAppConfig(viper *viper.Viper) *AppConfig {
+	config, err := LoadAppConfig(viper)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Fatal error config file (%s):\n%v\n", viper.ConfigFileUsed(), err)
+		os.Exit(1)
+	}
+	return config
+}
+
+// LoadAppConfig sama seperti NewAppConfig tapi mengembalikan error,
+// dipakai juga saat config dibaca ulang
+func LoadAppConfig(viper *viper.Viper) (*AppConfig, error) {
+	viper.SetDefault("tracing.sampleRatio", 1)
+
+	config := new(AppConfig)
+	// UnmarshalExact menolak key yang tidak dikenal, jadi typo di config.json ketahuan
+	if err := viper.UnmarshalExact(config); err != nil {
+		return nil, err
+	}
+
+	if err := newConfigValidator().Struct(config); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return nil, err
+		}
+
+		messages := make([]string, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			messages = append(messages, " - "+configErrorMessage(fe))
+		}
+		return nil, errors.New(strings.Join(messages, "\n"))
+	}
+
+	return config, nil
+}
+
+// newConfigValidator memakai nama key config (mapstructure) di pesan error
+func newConfigValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("mapstructure"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+	registerCustomValidations(validate)
+	validate.RegisterStructValidation(validateUploadConfig, UploadConfig{})
+	validate.RegisterStructValidation(validateCacheConfig, CacheConfig{})
+	return validate
+}
+
+func configErrorMessage(fe validator.FieldError) string {
+	// Namespace berbentuk "AppConfig.database.port", buang nama struct root
+	key := fe.Namespace()
+	if idx := strings.Index(key, "."); idx != -1 {
+		key = key[idx+1:]
+	}
+
+	switch fe.Tag() {
+	case "required", "required_unless":
+		return fmt.Sprintf("%s: wajib diisi", key)
+	case "oneof":
+		return fmt.Sprintf("%s: %q tidak valid, harus salah satu dari [%s]", key, fe.Value(), fe.Param())
+	default:
+		return fmt.Sprintf("%s: %v tidak valid (%s=%s)", key, fe.Value(), fe.Tag(), fe.Param())
+	}
+}
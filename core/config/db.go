@@ -1,44 +1,45 @@
 package config
 
 import (
+	"backend/core/metrics"
+	"backend/core/tracing"
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	gormutils "gorm.io/gorm/utils"
 )
 
-func NewDatabase(viper *viper.Viper, logrusLogger *logrus.Logger) *gorm.DB {
-	username := viper.GetString("database.username")
-	password := viper.GetString("database.password")
-	host := viper.GetString("database.host")
-	port := viper.GetInt("database.port")
-	database := viper.GetString("database.name")
-	idleConnection := viper.GetInt("database.pool.idle")
-	maxConnection := viper.GetInt("database.pool.max")
-	maxLifeTimeConnection := viper.GetInt("database.pool.lifetime")
+func NewDatabase(config *AppConfig, logrusLogger *logrus.Logger, m *metrics.Metrics) *gorm.DB {
+	username := config.Database.Username
+	password := config.Database.Password
+	host := config.Database.Host
+	port := config.Database.Port
+	database := config.Database.Name
+	idleConnection := config.Database.Pool.Idle
+	maxConnection := config.Database.Pool.Max
+	maxLifeTimeConnection := config.Database.Pool.Lifetime
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		username, password, host, port, database)
 
-	// Buat writer untuk GORM logger — arahkan output ke logrus
-	gormWriter := log.New(logrusWriter{Logger: logrusLogger}, "", 0)
-
-	// Setup GORM logger agar menampilkan query lengkap + value
-	gormLogger := logger.New(
-		gormWriter,
-		logger.Config{
+	// Setup GORM logger agar menampilkan query lengkap + value, diarahkan ke logrus
+	// lewat context supaya request_id ikut tercatat di setiap query
+	gormLogger := &logrusGormLogger{
+		Logger: logrusLogger,
+		Config: logger.Config{
 			SlowThreshold:             2 * time.Second, // query lambat
 			LogLevel:                  logger.Info,     // tampilkan semua query
-			Colorful:                  true,
 			IgnoreRecordNotFoundError: true,
 			ParameterizedQueries:      false, // ❗ ubah ke false agar value ditampilkan
 		},
-	)
+	}
 
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: gormLogger,
@@ -47,11 +48,24 @@ func NewDatabase(viper *viper.Viper, logrusLogger *logrus.Logger) *gorm.DB {
 		logrusLogger.Fatalf("failed to connect database: %v", err)
 	}
 
+	// Catat durasi & error query ke prometheus
+	if err := db.Use(metrics.NewGormPlugin(m)); err != nil {
+		logrusLogger.Fatalf("failed to register metrics plugin: %v", err)
+	}
+
+	// Child span per query, aktif hanya jika tracing dinyalakan
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		logrusLogger.Fatalf("failed to register tracing plugin: %v", err)
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		logrusLogger.Fatalf("failed to connect database: %v", err)
 	}
 
+	// Statistik pool koneksi (in use, idle, wait count)
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, database))
+
 	sqlDB.SetMaxIdleConns(idleConnection)
 	sqlDB.SetMaxOpenConns(maxConnection)
 	sqlDB.SetConnMaxLifetime(time.Second * time.Duration(maxLifeTimeConnection))
@@ -59,12 +73,57 @@ func NewDatabase(viper *viper.Viper, logrusLogger *logrus.Logger) *gorm.DB {
 	return db
 }
 
-type logrusWriter struct {
+// logrusGormLogger mengimplementasikan logger.Interface milik GORM dan meneruskan
+// context ke logrus, sehingga hook request_id bisa membaca context query
+type logrusGormLogger struct {
 	Logger *logrus.Logger
+	logger.Config
+}
+
+func (l *logrusGormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *l
+	newLogger.LogLevel = level
+	return &newLogger
+}
+
+func (l *logrusGormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Info {
+		l.Logger.WithContext(ctx).Infof(msg, data...)
+	}
 }
 
-// log.New() membutuhkan interface io.Writer, jadi kita implementasikan Write()
-func (l logrusWriter) Write(p []byte) (n int, err error) {
-	l.Logger.Trace(string(p))
-	return len(p), nil
+func (l *logrusGormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Warn {
+		l.Logger.WithContext(ctx).Warnf(msg, data...)
+	}
+}
+
+func (l *logrusGormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Error {
+		l.Logger.WithContext(ctx).Errorf(msg, data...)
+	}
+}
+
+func (l *logrusGormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.LogLevel <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	entry := l.Logger.WithContext(ctx).WithFields(logrus.Fields{
+		"source":  gormutils.FileWithLineNum(),
+		"elapsed": elapsed.String(),
+		"rows":    rows,
+		"sql":     sql,
+	})
+
+	switch {
+	case err != nil && l.LogLevel >= logger.Error && (!errors.Is(err, gorm.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError):
+		entry.WithError(err).Error("gorm query failed")
+	case l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.LogLevel >= logger.Warn:
+		entry.Warnf("slow query >= %v", l.SlowThreshold)
+	case l.LogLevel == logger.Info:
+		entry.Trace("gorm query")
+	}
 }
@@ -1,32 +1,85 @@
 package config
 
 import (
+	"backend/core/utils"
+	"errors"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/spf13/viper"
+	"github.com/sirupsen/logrus"
 )
 
-func NewFiber(config *viper.Viper) *fiber.App {
+func NewFiber(config *AppConfig, log *logrus.Logger, translator *ut.UniversalTranslator) *fiber.App {
 	var app = fiber.New(fiber.Config{
-		AppName:      config.GetString("app.name"),
-		ErrorHandler: NewErrorHandler(),
-		Prefork:      config.GetBool("web.prefork"),
+		AppName:      config.App.Name,
+		ErrorHandler: NewErrorHandler(log, translator),
+		Prefork:      config.Web.Prefork,
 	})
 
+	// file upload (logo cabang, dst) disajikan langsung dari folder upload,
+	// driver s3 disajikan oleh bucket
+	if config.Upload.Driver == "local" {
+		app.Static(utils.UploadsRoute, config.Upload.Dir, fiber.Static{
+			MaxAge: 86400,
+		})
+	}
+
 	return app
 }
 
-func NewErrorHandler() fiber.ErrorHandler {
+func NewErrorHandler(log *logrus.Logger, translator *ut.UniversalTranslator) fiber.ErrorHandler {
 	return func(ctx *fiber.Ctx, err error) error {
-		code := fiber.StatusInternalServerError
-		if e, ok := err.(*fiber.Error); ok {
-			code = e.Code
+		appErr := utils.ToAppError(err)
+
+		// bahasa dari Accept-Language, default bahasa Indonesia
+		lang := ctx.AcceptsLanguages("id", "en")
+		if lang == "" {
+			lang = "id"
+		}
+
+		// salin details supaya AppError milik service tidak ikut berubah
+		details := append([]utils.FieldError{}, appErr.Details...)
+
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			details = translateValidationErrors(translator, lang, validationErrors)
+		}
+
+		for i := range details {
+			if details[i].Message == "" {
+				details[i].Message = utils.ErrorMessage(appErr.Code, lang)
+			}
 		}
 
-		return ctx.Status(code).JSON(fiber.Map{
-			"status":  false,
-			"message": "failed",
-			"code":    code,
-			"errors":  err.Error(),
+		message := appErr.Message
+		if message == "" {
+			message = utils.ErrorMessage(appErr.Code, lang)
+		}
+
+		if appErr.Status >= fiber.StatusInternalServerError {
+			log.WithContext(ctx.UserContext()).Errorf("Unhandled error : %+v", err)
+		}
+
+		return ctx.Status(appErr.Status).JSON(utils.ErrorResponse{
+			Status:  false,
+			Code:    appErr.Status,
+			Message: message,
+			Error:   appErr.Code,
+			Errors:  details,
+		})
+	}
+}
+
+func translateValidationErrors(translator *ut.UniversalTranslator, lang string, validationErrors validator.ValidationErrors) []utils.FieldError {
+	trans, _ := translator.GetTranslator(lang)
+
+	details := make([]utils.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		details = append(details, utils.FieldError{
+			Field:   fe.Field(),
+			Message: fe.Translate(trans),
 		})
 	}
+	return details
 }
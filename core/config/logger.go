@@ -1,20 +1,83 @@
 package config
 
 import (
+	"backend/core/utils"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 )
 
-func NewLogger(viper *viper.Viper) *logrus.Logger {
+func NewLogger(config *AppConfig) *logrus.Logger {
 	log := logrus.New()
 
-	log.SetLevel(logrus.Level(viper.GetInt32("log.level")))
-	log.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: "2006-01-02 15:04:05",
-		ForceColors:     true, // kasih warna biar gampang baca
-		PadLevelText:    true, // ratain level log
-	})
+	log.SetLevel(parseLogLevel(config.Log.Level))
+	log.SetOutput(newLogOutput(config.Log.Output))
+
+	switch strings.ToLower(config.Log.Format) {
+	case "json":
+		log.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+		})
+	default:
+		log.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+			ForceColors:     true, // kasih warna biar gampang baca
+			PadLevelText:    true, // ratain level log
+		})
+	}
+
+	log.AddHook(requestIDHook{})
 
 	return log
 }
+
+// parseLogLevel menerima nama level ("info", "debug", ...) maupun angka
+// lama (0-6) supaya config yang sudah ada tetap jalan
+func parseLogLevel(value string) logrus.Level {
+	if value == "" {
+		return logrus.InfoLevel
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return logrus.Level(n)
+	}
+	level, err := logrus.ParseLevel(value)
+	if err != nil {
+		return logrus.InfoLevel
+	}
+	return level
+}
+
+// newLogOutput menerima "stdout", "stderr", atau path file
+func newLogOutput(output string) io.Writer {
+	switch strings.ToLower(output) {
+	case "", "stdout":
+		return os.Stdout
+	case "stderr":
+		return os.Stderr
+	}
+
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logrus.Warnf("failed to open log file %s, fallback to stdout: %v", output, err)
+		return os.Stdout
+	}
+	return file
+}
+
+// requestIDHook menambahkan request_id ke setiap entry yang dibuat lewat log.WithContext(ctx)
+type requestIDHook struct{}
+
+func (h requestIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h requestIDHook) Fire(entry *logrus.Entry) error {
+	if requestID := utils.RequestIDFromContext(entry.Context); requestID != "" {
+		entry.Data["request_id"] = requestID
+	}
+	return nil
+}
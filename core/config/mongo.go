@@ -1,26 +1,26 @@
 package config
 
 import (
+	"backend/core/tracing"
 	"context"
 	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // NewMongoDb membuat koneksi MongoDB dengan konfigurasi pool, timeout, dan logging
-func NewMongoDb(viper *viper.Viper, logrusLogger *logrus.Logger) *mongo.Database {
-	databaseUrl := viper.GetString("mongo.url")
-	username := viper.GetString("mongo.username")
-	password := viper.GetString("mongo.password")
-	database := fmt.Sprintf("%s_%s", viper.GetString("database.name"), viper.GetString("app.development"))
-	idleConnection := viper.GetInt("mongo.pool.idle")
-	maxConnection := viper.GetInt("mongo.pool.max")
-	connectTimeout := viper.GetInt("mongo.pool.lifetime") // dalam detik
+func NewMongoDb(config *AppConfig, logrusLogger *logrus.Logger) *mongo.Database {
+	databaseUrl := config.Mongo.URL
+	username := config.Mongo.Username
+	password := config.Mongo.Password
+	database := fmt.Sprintf("%s_%s", config.Database.Name, config.App.Development)
+	idleConnection := config.Mongo.Pool.Idle
+	maxConnection := config.Mongo.Pool.Max
+	connectTimeout := config.Mongo.Pool.Lifetime // dalam detik
 
 	clientOptions := options.Client().ApplyURI(databaseUrl)
 
@@ -35,20 +35,25 @@ func NewMongoDb(viper *viper.Viper, logrusLogger *logrus.Logger) *mongo.Database
 	clientOptions.SetMaxPoolSize(uint64(maxConnection))
 	clientOptions.SetMinPoolSize(uint64(idleConnection))
 
-	// Monitoring command agar logrus bisa menangkap perintah ke MongoDB
+	// Monitoring command agar logrus bisa menangkap perintah ke MongoDB,
+	// sekaligus membuat span per command jika tracing dinyalakan
+	spans := tracing.NewMongoSpans()
 	clientOptions.Monitor = &event.CommandMonitor{
-		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
-			logrusLogger.WithFields(logrus.Fields{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			spans.Started(ctx, evt)
+			logrusLogger.WithContext(ctx).WithFields(logrus.Fields{
 				"command":  evt.CommandName,
 				"database": evt.DatabaseName,
 			}).Tracef("MongoDB command started: %v", evt.Command)
 		},
-		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
-			logrusLogger.WithField("command", evt.CommandName).
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			spans.Succeeded(evt)
+			logrusLogger.WithContext(ctx).WithField("command", evt.CommandName).
 				Tracef("MongoDB command succeeded in %v", evt.Duration)
 		},
-		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
-			logrusLogger.WithField("command", evt.CommandName).
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			spans.Failed(evt)
+			logrusLogger.WithContext(ctx).WithField("command", evt.CommandName).
 				Errorf("MongoDB command failed after %v: %v", evt.Duration, evt.Failure)
 		},
 	}
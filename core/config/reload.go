@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// staticKeys adalah prefix key yang hanya dibaca saat startup. Perubahan di key ini
+// ditolak saat reload dan nilai lama tetap dipakai sampai aplikasi di-restart
+var staticKeys = []string{"app.", "web.", "database.", "mongo.", "tracing.", "idempotency.", "outbox.", "webhook.", "cache.", "rateLimit.backend", "upload.driver", "upload.dir", "upload.baseUrl", "upload.s3.", "secret_key", "log.output", "log.format"}
+
+// ConfigReloader memantau file config dan menerapkan perubahan yang aman
+// (log level, CORS, provisioning, dst) tanpa restart
+type ConfigReloader struct {
+	Log *logrus.Logger
+
+	mu        sync.RWMutex
+	current   *AppConfig
+	files     []string
+	listeners []func(old, new *AppConfig)
+}
+
+func NewConfigReloader(viper *viper.Viper, config *AppConfig, log *logrus.Logger) *ConfigReloader {
+	return &ConfigReloader{
+		Log:     log,
+		current: config,
+		files:   overlayFiles(viper),
+	}
+}
+
+// Current mengembalikan config yang sedang berlaku
+func (r *ConfigReloader) Current() *AppConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// OnChange mendaftarkan fungsi yang dipanggil setiap reload berhasil
+func (r *ConfigReloader) OnChange(fn func(old, new *AppConfig)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Watch memantau folder config (file utama dan overlay) di background.
+// Yang dipantau folder-nya, bukan file, agar editor yang menulis ulang file
+// dan symlink ConfigMap tetap terdeteksi
+func (r *ConfigReloader) Watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		r.Log.Warnf("Config hot reload disabled : %+v", err)
+		return
+	}
+
+	watched := map[string]bool{}
+	for _, file := range r.files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		watched[abs] = true
+		dir := filepath.Dir(abs)
+		if err := watcher.Add(dir); err != nil {
+			r.Log.Warnf("Failed to watch config dir %s : %+v", dir, err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		// editor biasanya menulis beberapa event sekaligus, tunggu sebentar lalu reload sekali
+		var timer *time.Timer
+		for {
+			select {
+			case evt, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				abs, _ := filepath.Abs(evt.Name)
+				if !watched[abs] && !strings.HasPrefix(filepath.Base(evt.Name), "..") {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(500*time.Millisecond, r.Reload)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				r.Log.Warnf("Config watcher error : %+v", err)
+			}
+		}
+	}()
+}
+
+// Reload membaca ulang semua lapisan config dan menerapkan perubahan yang diizinkan
+func (r *ConfigReloader) Reload() {
+	v, err := loadViper()
+	if err != nil {
+		r.Log.Warnf("Config reload failed, keep current config : %+v", err)
+		return
+	}
+
+	next, err := LoadAppConfig(v)
+	if err != nil {
+		r.Log.Warnf("Config reload rejected, keep current config :\n%v", err)
+		return
+	}
+
+	r.mu.Lock()
+	old := r.current
+
+	diff := diffConfig(old, next)
+	if len(diff) == 0 {
+		r.mu.Unlock()
+		return
+	}
+
+	applied := make([]string, 0, len(diff))
+	for _, change := range diff {
+		if change.static {
+			r.Log.Warnf("Config %s changed (%s -> %s) but requires restart, ignored", change.key, change.old, change.new)
+			continue
+		}
+		applied = append(applied, fmt.Sprintf("%s: %s -> %s", change.key, change.old, change.new))
+	}
+
+	// nilai yang butuh restart dikembalikan ke nilai lama supaya Current() sesuai kondisi aplikasi
+	next.App = old.App
+	next.Web = old.Web
+	next.Database = old.Database
+	next.Mongo = old.Mongo
+	next.Tracing = old.Tracing
+	next.Idempotency = old.Idempotency
+	next.Outbox = old.Outbox
+	next.Webhook = old.Webhook
+	next.Cache = old.Cache
+	next.RateLimit.Backend = old.RateLimit.Backend
+	next.Upload.Driver = old.Upload.Driver
+	next.Upload.Dir = old.Upload.Dir
+	next.Upload.BaseURL = old.Upload.BaseURL
+	next.Upload.S3 = old.Upload.S3
+	next.SecretKey = old.SecretKey
+	next.Log.Output = old.Log.Output
+	next.Log.Format = old.Log.Format
+
+	r.current = next
+	listeners := append([]func(old, new *AppConfig){}, r.listeners...)
+	r.mu.Unlock()
+
+	if len(applied) == 0 {
+		return
+	}
+
+	for _, fn := range listeners {
+		fn(old, next)
+	}
+
+	r.Log.WithField("changes", applied).Infof("Config reloaded : %s", strings.Join(applied, ", "))
+}
+
+type configChange struct {
+	key    string
+	old    string
+	new    string
+	static bool
+}
+
+// diffConfig membandingkan dua config per key (nama mapstructure), secret disamarkan
+func diffConfig(old, new *AppConfig) []configChange {
+	oldValues := map[string]string{}
+	newValues := map[string]string{}
+	flattenConfig("", reflect.ValueOf(*old), oldValues)
+	flattenConfig("", reflect.ValueOf(*new), newValues)
+
+	keys := make([]string, 0, len(newValues))
+	for key := range newValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changes := make([]configChange, 0)
+	for _, key := range keys {
+		if oldValues[key] == newValues[key] {
+			continue
+		}
+
+		change := configChange{key: key, old: oldValues[key], new: newValues[key]}
+		if isSecretKey(key) {
+			change.old, change.new = "***", "***"
+		}
+		for _, prefix := range staticKeys {
+			if strings.HasPrefix(key, prefix) {
+				change.static = true
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func flattenConfig(prefix string, value reflect.Value, out map[string]string) {
+	typ := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.SplitN(field.Tag.Get("mapstructure"), ",", 2)[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		if value.Field(i).Kind() == reflect.Struct {
+			flattenConfig(key, value.Field(i), out)
+			continue
+		}
+		out[key] = fmt.Sprintf("%v", value.Field(i).Interface())
+	}
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "password") || strings.Contains(key, "secret")
+}
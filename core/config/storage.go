@@ -0,0 +1,49 @@
+package config
+
+import (
+	"backend/core/storage"
+	"backend/core/utils"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewStorage membuat storage file upload sesuai upload.driver
+func NewStorage(config *AppConfig, log *logrus.Logger) storage.Storage {
+	upload := config.Upload
+
+	if upload.Driver == "s3" {
+		store, err := storage.NewS3(storage.S3Config{
+			Endpoint:  upload.S3.Endpoint,
+			Region:    upload.S3.Region,
+			Bucket:    upload.S3.Bucket,
+			AccessKey: upload.S3.AccessKey,
+			SecretKey: upload.S3.SecretKey,
+			UseSSL:    upload.S3.UseSSL,
+			PublicURL: upload.S3.PublicURL,
+		})
+		if err != nil {
+			log.Fatalf("failed to create s3 storage: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// bucket yang salah nama lebih baik ketahuan saat startup daripada saat upload pertama
+		exists, err := store.Client.BucketExists(ctx, upload.S3.Bucket)
+		if err != nil {
+			log.Warnf("Failed to check bucket %s : %+v", upload.S3.Bucket, err)
+		} else if !exists {
+			log.Fatalf("bucket %s not found", upload.S3.Bucket)
+		}
+
+		return store
+	}
+
+	baseURL := upload.BaseURL
+	if baseURL == "" {
+		baseURL = utils.UploadsRoute
+	}
+	return storage.NewLocal(upload.Dir, baseURL)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// NewTracing memasang tracer provider global OpenTelemetry. Jika tracing.enabled
+// bernilai false, provider global tetap noop dan fungsi shutdown tidak melakukan apa-apa
+func NewTracing(config *AppConfig, log *logrus.Logger) func(context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !config.Tracing.Enabled {
+		return func(context.Context) error { return nil }
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch strings.ToLower(config.Tracing.Exporter) {
+	case "otlp":
+		opts := []otlptracehttp.Option{}
+		if endpoint := config.Tracing.Endpoint; endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if config.Tracing.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	}
+	if err != nil {
+		log.Fatalf("failed to create trace exporter: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.Tracing.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(config.App.Name),
+			semconv.DeploymentEnvironmentName(config.App.Development),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown
+}
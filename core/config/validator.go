@@ -1,10 +1,190 @@
 package config
 
 import (
+	"backend/core/outbox"
+	"backend/web/model"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // image alpine tidak punya zoneinfo, embed supaya iana_timezone tetap jalan
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
-	"github.com/spf13/viper"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	idtranslations "github.com/go-playground/validator/v10/translations/id"
 )
 
-func NewValidator(viper *viper.Viper) *validator.Validate {
-	return validator.New()
+// RoundPPNValues adalah nilai yang diterima kolom roundppn
+var RoundPPNValues = []string{"up", "down"}
+
+var (
+	// ID cabang disimpan di kolom varchar(10)
+	branchIDPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,10}$`)
+	// nomor telepon Indonesia: seluler (08xx) maupun telepon rumah/kantor dengan kode area (02x-09x)
+	phonePattern = regexp.MustCompile(`^(\+62|62|0)[2-9][0-9]{7,12}$`)
+	// nomor WhatsApp harus nomor seluler: 08xx / 628xx / +628xx
+	whatsappPattern = regexp.MustCompile(`^(\+62|62|0)8[1-9][0-9]{6,11}$`)
+	// nomor SIPA, mis. "503/0012/SIPA-DPMPTSP/2024" atau "19860101/SIPA_32.73/2023/2.014"
+	sipaPattern = regexp.MustCompile(`(?i)^[A-Z0-9./_ -]*SIPA[A-Z0-9./_ -]*$`)
+)
+
+var customTranslations = []struct {
+	tag string
+	id  string
+	en  string
+}{
+	{"branch_id", "{0} harus berupa huruf atau angka maksimal 10 karakter", "{0} must be letters or digits, at most 10 characters"},
+	{"phone_id", "{0} harus berupa nomor telepon Indonesia yang valid", "{0} must be a valid Indonesian phone number"},
+	{"whatsapp_id", "{0} harus berupa nomor WhatsApp Indonesia yang valid (08xx / 628xx)", "{0} must be a valid Indonesian WhatsApp number (08xx / 628xx)"},
+	{"iana_timezone", "{0} harus berupa nama zona waktu IANA, mis. Asia/Jakarta", "{0} must be an IANA time zone name, e.g. Asia/Jakarta"},
+	{"latlng", "{0} harus berformat lat,lng, mis. -6.914744,107.609810", "{0} must be in lat,lng format, e.g. -6.914744,107.609810"},
+	{"roundppn", "{0} harus salah satu dari [" + strings.Join(RoundPPNValues, " ") + "]", "{0} must be one of [" + strings.Join(RoundPPNValues, " ") + "]"},
+	{"sipa", "{0} harus berupa nomor SIPA yang valid", "{0} must be a valid SIPA number"},
+	{"webhook_event", "{0} harus salah satu dari [* " + strings.Join(model.BranchEventTypes, " ") + "]", "{0} must be one of [* " + strings.Join(model.BranchEventTypes, " ") + "]"},
+	{"chart_data", "jumlah {0} harus sama dengan jumlah labels", "{0} must have as many values as labels"},
+	{"http_url", "{0} harus berupa URL http atau https", "{0} must be an http or https URL"},
+}
+
+func NewValidator(config *AppConfig) *validator.Validate {
+	validate := validator.New()
+
+	// pakai nama field json di pesan error, mis. "email" bukan "Email"
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
+	registerCustomValidations(validate)
+
+	return validate
+}
+
+// registerCustomValidations mendaftarkan tag validasi khusus domain cabang,
+// dipakai juga oleh validator config
+func registerCustomValidations(validate *validator.Validate) {
+	validate.RegisterValidation("branch_id", matchPattern(branchIDPattern))
+	validate.RegisterValidation("phone_id", matchPattern(phonePattern))
+	validate.RegisterValidation("whatsapp_id", matchPattern(whatsappPattern))
+	validate.RegisterValidation("sipa", matchPattern(sipaPattern))
+	validate.RegisterValidation("iana_timezone", validateTimezone)
+	validate.RegisterValidation("latlng", validateLatLng)
+	validate.RegisterValidation("roundppn", validateRoundPPN)
+	validate.RegisterValidation("webhook_event", validateWebhookEvent)
+	validate.RegisterStructValidation(validateChart, model.Chart{})
+}
+
+func matchPattern(pattern *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return pattern.MatchString(fl.Field().String())
+	}
+}
+
+// validateTimezone hanya menerima nama IANA (Asia/Jakarta), bukan "Local" atau string kosong
+func validateTimezone(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// validateLatLng menerima "lat,lng" dengan lat -90..90 dan lng -180..180
+func validateLatLng(fl validator.FieldLevel) bool {
+	parts := strings.Split(fl.Field().String(), ",")
+	if len(parts) != 2 {
+		return false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return false
+	}
+
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return false
+	}
+
+	return true
+}
+
+func validateRoundPPN(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	for _, allowed := range RoundPPNValues {
+		if value == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWebhookEvent menerima tipe domain event yang dikenal atau "*" untuk semua event
+func validateWebhookEvent(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	return value == outbox.AllEvents || slices.Contains(model.BranchEventTypes, value)
+}
+
+// validateChart memastikan setiap series punya data sebanyak labels dan semuanya angka berhingga
+func validateChart(sl validator.StructLevel) {
+	chart := sl.Current().Interface().(model.Chart)
+	for i, series := range chart.Series {
+		valid := len(series.Data) == len(chart.Labels)
+		for _, value := range series.Data {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				valid = false
+			}
+		}
+		if !valid {
+			name := "series[" + strconv.Itoa(i) + "].data"
+			sl.ReportError(series.Data, name, name, "chart_data", "")
+		}
+	}
+}
+
+// NewTranslator mendaftarkan terjemahan pesan validator bahasa Indonesia dan Inggris
+func NewTranslator(validate *validator.Validate) *ut.UniversalTranslator {
+	idLocale := id.New()
+	translator := ut.New(idLocale, idLocale, en.New())
+
+	idTrans, _ := translator.GetTranslator("id")
+	if err := idtranslations.RegisterDefaultTranslations(validate, idTrans); err != nil {
+		panic(err)
+	}
+
+	enTrans, _ := translator.GetTranslator("en")
+	if err := entranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		panic(err)
+	}
+
+	for _, t := range customTranslations {
+		registerTranslation(validate, idTrans, t.tag, t.id)
+		registerTranslation(validate, enTrans, t.tag, t.en)
+	}
+
+	return translator
+}
+
+func registerTranslation(validate *validator.Validate, trans ut.Translator, tag string, text string) {
+	err := validate.RegisterTranslation(tag, trans,
+		func(ut ut.Translator) error {
+			return ut.Add(tag, text, true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			message, _ := ut.T(tag, fe.Field())
+			return message
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -1,25 +1,136 @@
 package config
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
+const envPrefix = "APP"
+
+var configFile = flag.String("config", "", "path file config (default: config.json di . atau ./cmd/api/deploy)")
+
 // NewViper is a function to load config from config.json
-// You can change the implementation, for example load from env file, consul, etcd, etc
+// Urutan prioritas (yang bawah menimpa yang atas):
+//  1. config.json (atau file dari flag --config / env APP_CONFIG)
+//  2. config.<env>.json di folder yang sama, env dari APP_ENV atau app.development
+//  3. config.local.json di folder yang sama (tidak masuk git)
+//  4. environment variable APP_<KEY>, mis. APP_DATABASE_PASSWORD untuk database.password
+//  5. APP_<KEY>_FILE berisi path file, mis. Docker secret di /run/secrets
 func NewViper() *viper.Viper {
-	config := viper.New()
-
-	config.SetConfigName("config")
-	config.SetConfigType("json")
-	config.AddConfigPath(".")
-	config.AddConfigPath("./cmd/api/deploy")
-	err := config.ReadInConfig()
+	if !flag.Parsed() {
+		flag.Parse()
+	}
 
+	config, err := loadViper()
 	if err != nil {
 		panic(fmt.Errorf("Fatal error config file: %w \n", err))
 	}
 
 	return config
 }
+
+// loadViper membaca ulang semua lapisan config dari awal, dipakai saat startup
+// maupun saat file config berubah
+func loadViper() (*viper.Viper, error) {
+	// BindStruct agar env APP_* tetap terbaca saat Unmarshal walau key tidak ada di file
+	config := viper.NewWithOptions(viper.ExperimentalBindStruct())
+	config.SetConfigType("json")
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv(envPrefix + "_CONFIG")
+	}
+
+	if path != "" {
+		config.SetConfigFile(path)
+	} else {
+		config.SetConfigName("config")
+		config.AddConfigPath(".")
+		config.AddConfigPath("./cmd/api/deploy")
+	}
+
+	if err := config.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	// override dari environment variable: database.password -> APP_DATABASE_PASSWORD.
+	// Dipasang sebelum overlay supaya APP_APP_DEVELOPMENT ikut menentukan file overlay
+	config.SetEnvPrefix(envPrefix)
+	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	config.AutomaticEnv()
+
+	// overlay per environment
+	for _, overlay := range overlayFiles(config)[1:] {
+		if err := mergeConfigFile(config, overlay); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applySecretFiles(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// overlayFiles mengembalikan file config utama diikuti file overlay-nya
+func overlayFiles(config *viper.Viper) []string {
+	main := config.ConfigFileUsed()
+	dir := filepath.Dir(main)
+
+	files := []string{main}
+
+	env := os.Getenv(envPrefix + "_ENV")
+	if env == "" {
+		env = config.GetString("app.development")
+	}
+	if env != "" {
+		files = append(files, filepath.Join(dir, fmt.Sprintf("config.%s.json", env)))
+	}
+
+	return append(files, filepath.Join(dir, "config.local.json"))
+}
+
+// mergeConfigFile menggabungkan file overlay jika ada, file yang tidak ada dilewati
+func mergeConfigFile(config *viper.Viper, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	if err := config.MergeConfig(file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// applySecretFiles membaca APP_<KEY>_FILE untuk setiap key yang dikenal viper,
+// jadi key secret tetap harus ada di config.json (boleh dengan nilai kosong)
+func applySecretFiles(config *viper.Viper) error {
+	replacer := strings.NewReplacer(".", "_")
+
+	for _, key := range config.AllKeys() {
+		envKey := envPrefix + "_" + strings.ToUpper(replacer.Replace(key)) + "_FILE"
+		path := os.Getenv(envKey)
+		if path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", envKey, err)
+		}
+		config.Set(key, strings.TrimSpace(string(content)))
+	}
+
+	return nil
+}
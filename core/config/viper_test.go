@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadViperOverlayFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	main := writeConfigFile(t, dir, "config.json", `{"app": {"development": "prod"}, "web": {"port": 1000}}`)
+	writeConfigFile(t, dir, "config.prod.json", `{"web": {"port": 2000}}`)
+	writeConfigFile(t, dir, "config.dev.json", `{"web": {"port": 3000}}`)
+	writeConfigFile(t, dir, "config.staging.json", `{"web": {"port": 4000}}`)
+
+	tests := []struct {
+		name        string
+		appEnv      string
+		development string
+		want        int
+	}{
+		{name: "from file", want: 2000},
+		{name: "APP_APP_DEVELOPMENT", development: "dev", want: 3000},
+		{name: "APP_ENV wins", appEnv: "staging", development: "dev", want: 4000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_CONFIG", main)
+			t.Setenv("APP_ENV", tt.appEnv)
+			if tt.development != "" {
+				t.Setenv("APP_APP_DEVELOPMENT", tt.development)
+			}
+
+			v, err := loadViper()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := v.GetInt("web.port"); got != tt.want {
+				t.Fatalf("web.port = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore menyimpan record di collection idempotency_keys. Record expired
+// dihapus otomatis oleh TTL index pada expires_at
+type MongoStore struct {
+	Collection *mongo.Collection
+}
+
+func NewMongoStore(ctx context.Context, db *mongo.Database) (*MongoStore, error) {
+	collection := db.Collection("idempotency_keys")
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoStore{Collection: collection}, nil
+}
+
+func (s *MongoStore) Get(ctx context.Context, key string) (*Record, error) {
+	record := new(Record)
+	err := s.Collection.FindOne(ctx, bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *MongoStore) Reserve(ctx context.Context, record *Record) error {
+	// TTL index berjalan per menit, jadi key expired dihapus manual dulu
+	if _, err := s.Collection.DeleteOne(ctx, bson.M{"_id": record.Key, "expires_at": bson.M{"$lte": time.Now()}}); err != nil {
+		return err
+	}
+
+	_, err := s.Collection.InsertOne(ctx, record)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrKeyExists
+	}
+	return err
+}
+
+func (s *MongoStore) Complete(ctx context.Context, record *Record) error {
+	_, err := s.Collection.UpdateOne(ctx, bson.M{"_id": record.Key, "created_at": record.CreatedAt}, bson.M{"$set": bson.M{
+		"completed":     true,
+		"status_code":   record.StatusCode,
+		"content_type":  record.ContentType,
+		"response_body": record.ResponseBody,
+		"expires_at":    record.ExpiresAt,
+	}})
+	return err
+}
+
+func (s *MongoStore) Release(ctx context.Context, record *Record) error {
+	_, err := s.Collection.DeleteOne(ctx, bson.M{"_id": record.Key, "created_at": record.CreatedAt})
+	return err
+}
+
+func (s *MongoStore) Purge(ctx context.Context) error {
+	_, err := s.Collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lte": time.Now()}})
+	return err
+}
@@ -0,0 +1,72 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const mysqlDuplicateEntry = 1062
+
+// MySQLStore menyimpan record di tabel idempotency_keys, aman dipakai
+// bersama oleh beberapa proses prefork maupun beberapa container
+type MySQLStore struct {
+	DB *gorm.DB
+}
+
+func NewMySQLStore(db *gorm.DB) *MySQLStore {
+	return &MySQLStore{DB: db}
+}
+
+func (s *MySQLStore) Get(ctx context.Context, key string) (*Record, error) {
+	record := new(Record)
+	err := s.DB.WithContext(ctx).
+		Where("`key` = ? AND expires_at > ?", key, time.Now()).
+		Take(record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *MySQLStore) Reserve(ctx context.Context, record *Record) error {
+	db := s.DB.WithContext(ctx)
+
+	// key lama yang sudah expired boleh dipakai ulang
+	if err := db.Where("`key` = ? AND expires_at <= ?", record.Key, time.Now()).Delete(new(Record)).Error; err != nil {
+		return err
+	}
+
+	err := db.Create(record).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.Is(err, gorm.ErrDuplicatedKey) || (errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry) {
+		return ErrKeyExists
+	}
+	return err
+}
+
+func (s *MySQLStore) Complete(ctx context.Context, record *Record) error {
+	return s.DB.WithContext(ctx).Model(new(Record)).
+		Where("`key` = ? AND created_at = ?", record.Key, record.CreatedAt).
+		Updates(map[string]interface{}{
+			"completed":     true,
+			"status_code":   record.StatusCode,
+			"content_type":  record.ContentType,
+			"response_body": record.ResponseBody,
+			"expires_at":    record.ExpiresAt,
+		}).Error
+}
+
+func (s *MySQLStore) Release(ctx context.Context, record *Record) error {
+	return s.DB.WithContext(ctx).Where("`key` = ? AND created_at = ?", record.Key, record.CreatedAt).Delete(new(Record)).Error
+}
+
+func (s *MySQLStore) Purge(ctx context.Context) error {
+	return s.DB.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(new(Record)).Error
+}
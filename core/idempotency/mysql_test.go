@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestStore menjalankan MySQLStore di atas SQLite in-memory, TranslateError
+// mengubah error unique constraint menjadi gorm.ErrDuplicatedKey
+func newTestStore(t *testing.T) *MySQLStore {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard, TranslateError: true})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&Record{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewMySQLStore(db)
+}
+
+func newRecord(key string, lease time.Duration) *Record {
+	now := time.Now().Truncate(time.Millisecond)
+	return &Record{Key: key, RequestHash: "hash", CreatedAt: now, ExpiresAt: now.Add(lease)}
+}
+
+func TestMySQLStoreReserveComplete(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	record := newRecord("k1", time.Minute)
+	if err := s.Reserve(ctx, record); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := s.Reserve(ctx, newRecord("k1", time.Minute)); !errors.Is(err, ErrKeyExists) {
+		t.Fatalf("second Reserve: %v, want ErrKeyExists", err)
+	}
+
+	got, err := s.Get(ctx, "k1")
+	if err != nil || got == nil || got.Completed {
+		t.Fatalf("Get reserved: %+v, %v; want in progress record", got, err)
+	}
+
+	record.Completed = true
+	record.StatusCode = 201
+	record.ContentType = "application/json"
+	record.ResponseBody = []byte(`{"ok":true}`)
+	record.ExpiresAt = time.Now().Add(time.Hour)
+	if err := s.Complete(ctx, record); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	got, err = s.Get(ctx, "k1")
+	if err != nil || got == nil || !got.Completed || got.StatusCode != 201 || string(got.ResponseBody) != `{"ok":true}` {
+		t.Fatalf("Get completed: %+v, %v", got, err)
+	}
+	// Complete memperpanjang expires_at dari lease ke ttl
+	if got.ExpiresAt.Before(time.Now().Add(59 * time.Minute)) {
+		t.Fatalf("expires at %s, want about 1h from now", got.ExpiresAt)
+	}
+}
+
+func TestMySQLStoreLeaseExpired(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	// request pertama mati tanpa Complete/Release, lease sudah lewat
+	stale := newRecord("k1", -time.Second)
+	if err := s.Reserve(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Get(ctx, "k1"); err != nil || got != nil {
+		t.Fatalf("Get after lease: %+v, %v; want nil", got, err)
+	}
+
+	// retry mengambil alih key
+	retry := newRecord("k1", time.Minute)
+	retry.CreatedAt = retry.CreatedAt.Add(time.Millisecond)
+	if err := s.Reserve(ctx, retry); err != nil {
+		t.Fatalf("Reserve after lease: %v", err)
+	}
+
+	// request lama yang ternyata selesai belakangan tidak menimpa atau menghapus reservasi baru
+	stale.Completed = true
+	stale.StatusCode = 200
+	stale.ExpiresAt = time.Now().Add(time.Hour)
+	if err := s.Complete(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Release(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Get(ctx, "k1")
+	if err != nil || got == nil || got.Completed {
+		t.Fatalf("Get: %+v, %v; want retry reservation still in progress", got, err)
+	}
+
+	if err := s.Release(ctx, retry); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Get(ctx, "k1"); err != nil || got != nil {
+		t.Fatalf("Get after release: %+v, %v; want nil", got, err)
+	}
+}
+
+func TestMySQLStorePurge(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	if err := s.Reserve(ctx, newRecord("old", -time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reserve(ctx, newRecord("new", time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Purge(ctx); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+
+	var keys []string
+	if err := s.DB.Model(&Record{}).Pluck("key", &keys).Error; err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(keys) != "[new]" {
+		t.Fatalf("keys %v, want [new]", keys)
+	}
+}
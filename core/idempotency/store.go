@@ -0,0 +1,60 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrKeyExists dikembalikan Reserve jika key sudah dipakai request lain
+var ErrKeyExists = errors.New("idempotency key already exists")
+
+// Record menyimpan hash request dan response yang sudah dikirim untuk satu Idempotency-Key
+type Record struct {
+	Key          string    `gorm:"column:key;primaryKey;size:64" bson:"_id"`
+	RequestHash  string    `gorm:"column:request_hash;size:64;not null" bson:"request_hash"`
+	Completed    bool      `gorm:"column:completed;not null;default:0" bson:"completed"`
+	StatusCode   int       `gorm:"column:status_code;not null;default:0" bson:"status_code"`
+	ContentType  string    `gorm:"column:content_type;size:100" bson:"content_type"`
+	ResponseBody []byte    `gorm:"column:response_body;type:longblob" bson:"response_body"`
+	CreatedAt    time.Time `gorm:"column:created_at;not null" bson:"created_at"`
+	ExpiresAt    time.Time `gorm:"column:expires_at;not null;index" bson:"expires_at"`
+}
+
+func (r *Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// Store adalah backend penyimpanan record idempotency (MySQL atau Mongo)
+type Store interface {
+	// Get mengembalikan record yang belum expired, nil jika tidak ada
+	Get(ctx context.Context, key string) (*Record, error)
+	// Reserve menyimpan record baru yang belum selesai, ErrKeyExists jika key sudah ada.
+	// ExpiresAt record yang belum selesai adalah lease, setelah lewat key boleh di-reserve ulang
+	Reserve(ctx context.Context, record *Record) error
+	// Complete menyimpan response dan ExpiresAt baru untuk record yang sudah di-reserve.
+	// Reservasi yang sudah diambil alih request lain (CreatedAt berbeda) tidak diubah
+	Complete(ctx context.Context, record *Record) error
+	// Release menghapus reservasi, dipakai jika request gagal supaya bisa diulang
+	Release(ctx context.Context, record *Record) error
+	// Purge menghapus record yang sudah expired
+	Purge(ctx context.Context) error
+}
+
+// StartPurge menghapus record expired secara berkala di background
+func StartPurge(store Store, interval time.Duration, log *logrus.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if err := store.Purge(ctx); err != nil {
+				log.Warnf("Failed to purge idempotency keys : %+v", err)
+			}
+			cancel()
+		}
+	}()
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"backend/web/repository"
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// BusinessCollector menghitung gauge bisnis (cabang per status dan jumlah manajemen)
+// langsung dari database setiap kali /metrics di-scrape
+type BusinessCollector struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	BranchRepository *repository.BranchsRepository
+
+	branches    *prometheus.Desc
+	managements *prometheus.Desc
+}
+
+func NewBusinessCollector(db *gorm.DB, log *logrus.Logger, branchRepository *repository.BranchsRepository) *BusinessCollector {
+	return &BusinessCollector{
+		DB:               db,
+		Log:              log,
+		BranchRepository: branchRepository,
+		branches: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "business", "branches"),
+			"Jumlah cabang per status (trial, paid, expired, deleted).",
+			[]string{"state"}, nil,
+		),
+		managements: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "business", "managements"),
+			"Jumlah manajemen yang masih aktif.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *BusinessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.branches
+	ch <- c.managements
+}
+
+func (c *BusinessCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := c.BranchRepository.CountByState(c.DB.WithContext(ctx))
+	if err != nil {
+		c.Log.Warnf("Failed to collect business metrics : %+v", err)
+		ch <- prometheus.NewInvalidMetric(c.branches, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.branches, prometheus.GaugeValue, float64(count.Trial), "trial")
+	ch <- prometheus.MustNewConstMetric(c.branches, prometheus.GaugeValue, float64(count.Paid), "paid")
+	ch <- prometheus.MustNewConstMetric(c.branches, prometheus.GaugeValue, float64(count.Expired), "expired")
+	ch <- prometheus.MustNewConstMetric(c.branches, prometheus.GaugeValue, float64(count.Deleted), "deleted")
+	ch <- prometheus.MustNewConstMetric(c.managements, prometheus.GaugeValue, float64(count.Managements))
+}
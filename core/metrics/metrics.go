@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "panel"
+
+// Metrics menyimpan registry prometheus beserta collector yang dipakai aplikasi
+type Metrics struct {
+	Registry        *prometheus.Registry
+	HTTPRequests    *prometheus.CounterVec
+	HTTPDuration    *prometheus.HistogramVec
+	DBQueryDuration *prometheus.HistogramVec
+	DBQueryErrors   *prometheus.CounterVec
+	CacheRequests   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		Registry: registry,
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Jumlah request HTTP per method, route dan status.",
+		}, []string{"method", "route", "status"}),
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency request HTTP per method, route dan status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		DBQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Durasi query GORM per operasi dan tabel.",
+			Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"operation", "table"}),
+		DBQueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_errors_total",
+			Help:      "Jumlah query GORM yang gagal per operasi dan tabel.",
+		}, []string{"operation", "table"}),
+		CacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "requests_total",
+			Help:      "Jumlah lookup cache per cache dan hasil (hit, remote_hit, miss).",
+		}, []string{"cache", "result"}),
+	}
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPRequests,
+		m.HTTPDuration,
+		m.DBQueryDuration,
+		m.DBQueryErrors,
+		m.CacheRequests,
+	)
+
+	return m
+}
+
+// ObserveRequest mencatat satu request HTTP yang sudah selesai
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.HTTPRequests.WithLabelValues(method, route, code).Inc()
+	m.HTTPDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveCache mencatat satu lookup cache
+func (m *Metrics) ObserveCache(name, result string) {
+	m.CacheRequests.WithLabelValues(name, result).Inc()
+}
+
+// Handler mengembalikan handler fiber untuk endpoint /metrics
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+}
@@ -1,28 +1,47 @@
 package middlewares
 
 import (
+	"backend/core/metrics"
+	"backend/core/utils"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
-func RequestLogger(log *logrus.Logger) fiber.Handler {
+func RequestLogger(log *logrus.Logger, m *metrics.Metrics) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
 
 		// lanjut ke handler berikutnya
 		err := c.Next()
 
+		duration := time.Since(start)
+		status := responseStatus(c, err)
+
 		// log setelah response selesai
-		log.Infof("[%s] %s | Status: %d | IP: %s | Duration: %v",
-			c.Method(),
-			c.OriginalURL(),
-			c.Response().StatusCode(),
-			c.IP(),
-			time.Since(start),
-		)
+		log.WithContext(c.UserContext()).WithFields(logrus.Fields{
+			"method":   c.Method(),
+			"url":      c.OriginalURL(),
+			"status":   status,
+			"ip":       c.IP(),
+			"duration": duration.String(),
+		}).Infof("[%s] %s | Status: %d", c.Method(), c.OriginalURL(), status)
+
+		// pakai path route (bukan URL asli) agar label metrics tidak meledak
+		m.ObserveRequest(c.Method(), c.Route().Path, status, duration)
 
 		return err
 	}
 }
+
+// responseStatus mengambil status akhir response, termasuk error yang
+// baru akan ditulis oleh ErrorHandler setelah middleware ini selesai.
+// Memakai utils.ToAppError yang sama dengan ErrorHandler supaya status di log,
+// metrics dan trace sama dengan yang diterima client
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	return utils.ToAppError(err).Status
+}
@@ -0,0 +1,181 @@
+package middlewares
+
+import (
+	"backend/core/idempotency"
+	"backend/core/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// Idempotency menyimpan response request POST/PUT/PATCH/DELETE yang membawa header
+// Idempotency-Key selama ttl. Retry dengan key dan body yang sama mendapat response
+// yang sama, key yang sama dengan body berbeda ditolak dengan 409. Request yang masih
+// diproses memegang key selama lease, lewat dari itu (mis. proses mati) retry boleh mengambil alih
+func Idempotency(store idempotency.Store, ttl time.Duration, lease time.Duration, log *logrus.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(c.Method()) {
+			return c.Next()
+		}
+		if len(key) > 255 {
+			return utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Idempotency-Key maksimal 255 karakter")
+		}
+
+		ctx := c.UserContext()
+		// key di-scope per method, path dan pemilik token supaya tidak bentrok antar endpoint/user
+		scopedKey := hashParts(c.Method(), c.Path(), c.Get(fiber.HeaderAuthorization), key)
+		requestHash, err := hashRequest(c)
+		if err != nil {
+			return err
+		}
+
+		record, err := store.Get(ctx, scopedKey)
+		if err != nil {
+			return err
+		}
+		if record != nil {
+			return replay(c, record, requestHash)
+		}
+
+		// presisi milidetik sama dengan kolom created_at, dipakai store untuk mengenali reservasi ini
+		now := time.Now().Truncate(time.Millisecond)
+		record = &idempotency.Record{
+			Key:         scopedKey,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(lease),
+		}
+		if err := store.Reserve(ctx, record); err != nil {
+			if errors.Is(err, idempotency.ErrKeyExists) {
+				return utils.NewAppError(fiber.StatusConflict, utils.ErrCodeIdempotencyInProgress, "")
+			}
+			return err
+		}
+
+		// error dirender di sini (bukan di ErrorHandler global) supaya body error ikut tersimpan
+		if err := c.Next(); err != nil {
+			if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+				_ = store.Release(ctx, record)
+				return handlerErr
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			// error server tidak disimpan supaya client bisa retry
+			if err := store.Release(ctx, record); err != nil {
+				log.WithContext(ctx).Warnf("Failed to release idempotency key : %+v", err)
+			}
+			return nil
+		}
+
+		record.Completed = true
+		record.StatusCode = status
+		record.ContentType = string(c.Response().Header.ContentType())
+		record.ResponseBody = append([]byte{}, c.Response().Body()...)
+		record.ExpiresAt = time.Now().Add(ttl)
+		if err := store.Complete(ctx, record); err != nil {
+			log.WithContext(ctx).Warnf("Failed to save idempotency response : %+v", err)
+		}
+
+		return nil
+	}
+}
+
+func replay(c *fiber.Ctx, record *idempotency.Record, requestHash string) error {
+	if record.RequestHash != requestHash {
+		return utils.NewAppError(fiber.StatusConflict, utils.ErrCodeIdempotencyReused, "")
+	}
+	if !record.Completed {
+		return utils.NewAppError(fiber.StatusConflict, utils.ErrCodeIdempotencyInProgress, "")
+	}
+
+	c.Set(IdempotencyReplayedHeader, "true")
+	if record.ContentType != "" {
+		c.Set(fiber.HeaderContentType, record.ContentType)
+	}
+	return c.Status(record.StatusCode).Send(record.ResponseBody)
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// hashRequest membuat hash method, path dan body. Body multipart di-hash per field dan isi
+// file karena boundary berbeda di setiap request walau isinya sama
+func hashRequest(c *fiber.Ctx) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(c.Get(fiber.HeaderContentType)), fiber.MIMEMultipartForm) {
+		return hashParts(c.Method(), c.Path(), string(c.Body())), nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return "", utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Form multipart tidak valid")
+	}
+
+	parts := []string{c.Method(), c.Path()}
+	for _, name := range sortedKeys(form.Value) {
+		parts = append(parts, "value", name)
+		parts = append(parts, form.Value[name]...)
+	}
+	for _, name := range sortedKeys(form.File) {
+		for _, header := range form.File[name] {
+			sum, err := hashFile(header)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, "file", name, header.Filename, sum)
+		}
+	}
+	return hashParts(parts...), nil
+}
+
+func hashFile(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,254 @@
+package middlewares
+
+import (
+	"backend/core/idempotency"
+	"backend/core/utils"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryIdempotencyStore meniru perilaku MySQLStore di memory untuk test middleware
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: map[string]idempotency.Record{}}
+}
+
+func (s *memoryIdempotencyStore) Get(ctx context.Context, key string) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || !record.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, record *idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[record.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return idempotency.ErrKeyExists
+	}
+	s.records[record.Key] = *record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, record *idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[record.Key]; ok && existing.CreatedAt.Equal(record.CreatedAt) {
+		s.records[record.Key] = *record
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, record *idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[record.Key]; ok && existing.CreatedAt.Equal(record.CreatedAt) {
+		delete(s.records, record.Key)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Purge(ctx context.Context) error {
+	return nil
+}
+
+// expireLeases membuat semua reservasi yang belum selesai seolah lease-nya sudah lewat
+func (s *memoryIdempotencyStore) expireLeases() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, record := range s.records {
+		if !record.Completed {
+			record.ExpiresAt = time.Now().Add(-time.Second)
+			s.records[key] = record
+		}
+	}
+}
+
+func newIdempotencyApp(store idempotency.Store, handler fiber.Handler) *fiber.App {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			return c.Status(appErr.Status).SendString(appErr.Code)
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}})
+	app.Use(Idempotency(store, time.Hour, time.Minute, log))
+	app.Post("/branch", handler)
+	app.Post("/branch/:id/logo", handler)
+	return app
+}
+
+type idempotencyResponse struct {
+	status   int
+	body     string
+	replayed bool
+}
+
+func doIdempotent(t *testing.T, app *fiber.App, path string, contentType string, body []byte, key string) idempotencyResponse {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, contentType)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	return idempotencyResponse{resp.StatusCode, string(raw), resp.Header.Get(IdempotencyReplayedHeader) == "true"}
+}
+
+func countingHandler(calls *int, status int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		*calls++
+		return c.Status(status).SendString("created")
+	}
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	calls := 0
+	app := newIdempotencyApp(newMemoryIdempotencyStore(), countingHandler(&calls, fiber.StatusCreated))
+
+	first := doIdempotent(t, app, "/branch", fiber.MIMEApplicationJSON, []byte(`{"id":"A1"}`), "key-1")
+	second := doIdempotent(t, app, "/branch", fiber.MIMEApplicationJSON, []byte(`{"id":"A1"}`), "key-1")
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	if first.status != fiber.StatusCreated || first.replayed {
+		t.Fatalf("first %+v, want 201 not replayed", first)
+	}
+	if second.status != fiber.StatusCreated || second.body != "created" || !second.replayed {
+		t.Fatalf("second %+v, want replayed 201", second)
+	}
+
+	// key sama dengan body berbeda ditolak
+	reused := doIdempotent(t, app, "/branch", fiber.MIMEApplicationJSON, []byte(`{"id":"B2"}`), "key-1")
+	if reused.status != fiber.StatusConflict || reused.body != utils.ErrCodeIdempotencyReused {
+		t.Fatalf("reused %+v, want 409 %s", reused, utils.ErrCodeIdempotencyReused)
+	}
+
+	// tanpa header tidak disimpan
+	doIdempotent(t, app, "/branch", fiber.MIMEApplicationJSON, []byte(`{"id":"A1"}`), "")
+	doIdempotent(t, app, "/branch", fiber.MIMEApplicationJSON, []byte(`{"id":"A1"}`), "")
+	if calls != 3 {
+		t.Fatalf("handler called %d times, want 3", calls)
+	}
+}
+
+func TestIdempotencyServerErrorReleased(t *testing.T) {
+	calls := 0
+	app := newIdempotencyApp(newMemoryIdempotencyStore(), countingHandler(&calls, fiber.StatusServiceUnavailable))
+
+	for i := 0; i < 2; i++ {
+		if resp := doIdempotent(t, app, "/branch", fiber.MIMEApplicationJSON, []byte(`{}`), "key-1"); resp.replayed {
+			t.Fatalf("attempt %d replayed 5xx response", i+1)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("handler called %d times, want 2 (5xx not stored)", calls)
+	}
+}
+
+func TestIdempotencyInProgressLease(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	calls := 0
+	app := newIdempotencyApp(store, countingHandler(&calls, fiber.StatusCreated))
+
+	// request lain masih memegang key (mis. proses mati di tengah request)
+	now := time.Now().Truncate(time.Millisecond)
+	scopedKey := hashParts(fiber.MethodPost, "/branch", "", "key-1")
+	requestHash := hashParts(fiber.MethodPost, "/branch", `{}`)
+	store.records[scopedKey] = idempotency.Record{Key: scopedKey, RequestHash: requestHash, CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+
+	resp := doIdempotent(t, app, "/branch", fiber.MIMEApplicationJSON, []byte(`{}`), "key-1")
+	if resp.status != fiber.StatusConflict || resp.body != utils.ErrCodeIdempotencyInProgress {
+		t.Fatalf("during lease %+v, want 409 %s", resp, utils.ErrCodeIdempotencyInProgress)
+	}
+
+	// lease lewat, retry mengambil alih key dan hasilnya disimpan untuk ttl penuh
+	store.expireLeases()
+	resp = doIdempotent(t, app, "/branch", fiber.MIMEApplicationJSON, []byte(`{}`), "key-1")
+	if resp.status != fiber.StatusCreated || resp.replayed || calls != 1 {
+		t.Fatalf("after lease %+v (calls %d), want handled 201", resp, calls)
+	}
+	record := store.records[scopedKey]
+	if !record.Completed || record.ExpiresAt.Before(time.Now().Add(59*time.Minute)) {
+		t.Fatalf("record %+v, want completed with ttl expiry", record)
+	}
+}
+
+func multipartBody(t *testing.T, fields map[string]string, file string, content []byte) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	part, err := writer.CreateFormFile("logo", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+	return buf.Bytes(), writer.FormDataContentType()
+}
+
+func TestIdempotencyMultipartRetry(t *testing.T) {
+	calls := 0
+	app := newIdempotencyApp(newMemoryIdempotencyStore(), func(c *fiber.Ctx) error {
+		calls++
+		// handler tetap bisa membaca file setelah middleware mem-parse form
+		header, err := c.FormFile("logo")
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusOK).SendString(header.Filename)
+	})
+
+	// setiap body multipart punya boundary acak, retry dengan isi sama tetap di-replay
+	first, firstType := multipartBody(t, map[string]string{"note": "a"}, "logo.png", []byte("png-1"))
+	retry, retryType := multipartBody(t, map[string]string{"note": "a"}, "logo.png", []byte("png-1"))
+	if firstType == retryType {
+		t.Fatal("test needs different boundaries")
+	}
+
+	resp := doIdempotent(t, app, "/branch/1/logo", firstType, first, "key-1")
+	if resp.status != fiber.StatusOK || resp.body != "logo.png" {
+		t.Fatalf("first %+v, want 200 logo.png", resp)
+	}
+	resp = doIdempotent(t, app, "/branch/1/logo", retryType, retry, "key-1")
+	if resp.status != fiber.StatusOK || !resp.replayed || calls != 1 {
+		t.Fatalf("retry %+v (calls %d), want replayed 200", resp, calls)
+	}
+
+	// isi file berbeda tetap ditolak
+	other, otherType := multipartBody(t, map[string]string{"note": "a"}, "logo.png", []byte("png-2"))
+	resp = doIdempotent(t, app, "/branch/1/logo", otherType, other, "key-1")
+	if resp.status != fiber.StatusConflict || resp.body != utils.ErrCodeIdempotencyReused {
+		t.Fatalf("other file %+v, want 409 %s", resp, utils.ErrCodeIdempotencyReused)
+	}
+
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"backend/core/ratelimit"
+	"backend/core/utils"
+	"backend/web/model"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+type rateLimitCheck struct {
+	key   string
+	limit ratelimit.Limit
+}
+
+// RateLimit membatasi request per IP dan per user (jika sudah melewati NewAuth) dengan
+// token bucket. name membedakan bucket antar route group. rule dibaca setiap request
+// sehingga perubahan config langsung berlaku. Jika store error, request tetap dilayani
+func RateLimit(store ratelimit.Store, name string, rule func() ratelimit.Rule, log *logrus.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		current := rule()
+		ctx := c.UserContext()
+
+		checks := make([]rateLimitCheck, 0, 2)
+		if current.IP.Requests > 0 {
+			checks = append(checks, rateLimitCheck{name + ":ip:" + c.IP(), current.IP})
+		}
+		if auth, ok := c.Locals("auth").(*model.Auth); ok && current.User.Requests > 0 {
+			checks = append(checks, rateLimitCheck{name + ":user:" + auth.UID, current.User})
+		}
+		if len(checks) == 0 {
+			return c.Next()
+		}
+
+		// header mengikuti batas yang paling ketat
+		var tightest *ratelimit.Result
+		for _, check := range checks {
+			result, err := store.Take(ctx, check.key, check.limit)
+			if err != nil {
+				log.WithContext(ctx).Warnf("Rate limit store error, request allowed : %+v", err)
+				return c.Next()
+			}
+			if tightest == nil || !result.Allowed || (tightest.Allowed && result.Remaining < tightest.Remaining) {
+				r := result
+				tightest = &r
+			}
+			if !result.Allowed {
+				break
+			}
+		}
+
+		c.Set(RateLimitLimitHeader, strconv.Itoa(tightest.Limit))
+		c.Set(RateLimitRemainingHeader, strconv.Itoa(tightest.Remaining))
+		c.Set(RateLimitResetHeader, strconv.Itoa(int(tightest.Reset.Seconds())))
+
+		if !tightest.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(tightest.RetryAfter.Seconds())))
+			return utils.NewAppError(fiber.StatusTooManyRequests, utils.ErrCodeTooManyRequests, "")
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"backend/core/ratelimit"
+	"backend/web/model"
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// recordingStore mencatat key yang diminta lalu meneruskan ke MemoryStore
+type recordingStore struct {
+	*ratelimit.MemoryStore
+	keys []string
+}
+
+func (s *recordingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	s.keys = append(s.keys, key)
+	return s.MemoryStore.Take(ctx, key, limit)
+}
+
+func newRateLimitApp(store ratelimit.Store, rule ratelimit.Rule) *fiber.App {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		return c.SendStatus(fiber.StatusTooManyRequests)
+	}})
+	app.Use(func(c *fiber.Ctx) error {
+		if uid := c.Get("X-Test-UID"); uid != "" {
+			c.Locals("auth", &model.Auth{UID: uid})
+		}
+		return c.Next()
+	})
+	app.Use(RateLimit(store, "guest", func() ratelimit.Rule { return rule }, log))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	return app
+}
+
+func doRateLimited(t *testing.T, app *fiber.App, uid string) int {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if uid != "" {
+		req.Header.Set("X-Test-UID", uid)
+	}
+	resp, err := app.Test(req, int(time.Second.Milliseconds()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	rule := ratelimit.Rule{
+		IP:   ratelimit.Limit{Requests: 100, Period: 60},
+		User: ratelimit.Limit{Requests: 100, Period: 60},
+	}
+
+	tests := []struct {
+		name string
+		uid  string
+		want []string
+	}{
+		{"guest only ip", "", []string{"guest:ip:"}},
+		{"auth ip and user", "user-1", []string{"guest:ip:", "guest:user:user-1"}},
+	}
+	for _, tt := range tests {
+		store := &recordingStore{MemoryStore: ratelimit.NewMemoryStore()}
+		if status := doRateLimited(t, newRateLimitApp(store, rule), tt.uid); status != fiber.StatusOK {
+			t.Fatalf("%s: status %d, want 200", tt.name, status)
+		}
+		if len(store.keys) != len(tt.want) {
+			t.Fatalf("%s: keys %v, want %v", tt.name, store.keys, tt.want)
+		}
+		for i, prefix := range tt.want {
+			if !strings.HasPrefix(store.keys[i], prefix) {
+				t.Fatalf("%s: key %q, want prefix %q", tt.name, store.keys[i], prefix)
+			}
+		}
+	}
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	// batas user lebih ketat dari batas IP, user lain dari IP yang sama tetap dilayani
+	rule := ratelimit.Rule{
+		IP:   ratelimit.Limit{Requests: 100, Period: 60},
+		User: ratelimit.Limit{Requests: 1, Period: 60},
+	}
+	app := newRateLimitApp(ratelimit.NewMemoryStore(), rule)
+
+	if status := doRateLimited(t, app, "user-1"); status != fiber.StatusOK {
+		t.Fatalf("first request: %d, want 200", status)
+	}
+	if status := doRateLimited(t, app, "user-1"); status != fiber.StatusTooManyRequests {
+		t.Fatalf("second request same user: %d, want 429", status)
+	}
+	if status := doRateLimited(t, app, "user-2"); status != fiber.StatusOK {
+		t.Fatalf("other user: %d, want 200", status)
+	}
+}
+
+func TestRateLimitPerIP(t *testing.T) {
+	rule := ratelimit.Rule{IP: ratelimit.Limit{Requests: 1, Period: 60}}
+	app := newRateLimitApp(ratelimit.NewMemoryStore(), rule)
+
+	if status := doRateLimited(t, app, ""); status != fiber.StatusOK {
+		t.Fatalf("first request: %d, want 200", status)
+	}
+	// tanpa batas user, login tidak membuka bucket baru untuk IP yang sama
+	if status := doRateLimited(t, app, "user-1"); status != fiber.StatusTooManyRequests {
+		t.Fatalf("second request same ip: %d, want 429", status)
+	}
+}
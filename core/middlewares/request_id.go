@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"backend/core/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID mengambil X-Request-ID dari client atau membuat yang baru,
+// lalu menyimpannya di response header dan context agar ikut di setiap log
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(RequestIDHeader, requestID)
+		c.Locals("requestId", requestID)
+		c.SetUserContext(utils.WithRequestID(c.UserContext(), requestID))
+
+		return c.Next()
+	}
+}
+
+// isValidRequestID menolak id kosong, terlalu panjang, atau berisi karakter
+// aneh supaya header dari client tidak bisa menyisipkan isi ke log
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,255 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Operation mendeskripsikan satu route untuk dokumen OpenAPI.
+// Path memakai format fiber (/branch/:id), parameter path dibuat otomatis
+type Operation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tags        []string
+	Auth        bool
+	Query       []Parameter
+	Headers     []Parameter
+	Request     any
+	Response    any
+	// ContentType body request, default application/json
+	ContentType string
+	// Responses tambahan selain 200 dan error standar, mis. 412
+	Responses map[int]string
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*PathItem `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type PathItem struct {
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]map[string]any `json:"securitySchemes,omitempty"`
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+
+// Build membuat dokumen OpenAPI 3 dari daftar operation. errorResponse adalah
+// body yang dikirim ErrorHandler untuk semua response gagal
+func Build(info Info, errorResponse any, operations []Operation) *Document {
+	registry := newSchemaRegistry()
+	errorSchema := registry.SchemaOf(errorResponse)
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]map[string]*PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]map[string]any{
+				"bearerAuth": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+
+	for _, op := range operations {
+		path := NormalizePath(op.Path)
+		openAPIPath := pathParam.ReplaceAllString(path, "{$1}")
+
+		item := &PathItem{
+			Summary:     op.Summary,
+			Description: op.Description,
+			Tags:        op.Tags,
+			OperationID: operationID(op.Method, path),
+			Responses:   map[string]*Response{},
+		}
+
+		for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+			item.Parameters = append(item.Parameters, Parameter{
+				Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+		for _, q := range op.Query {
+			q.In = "query"
+			if q.Schema == nil {
+				q.Schema = &Schema{Type: "string"}
+			}
+			item.Parameters = append(item.Parameters, q)
+		}
+		for _, h := range op.Headers {
+			h.In = "header"
+			if h.Schema == nil {
+				h.Schema = &Schema{Type: "string"}
+			}
+			item.Parameters = append(item.Parameters, h)
+		}
+
+		if op.Request != nil {
+			contentType := op.ContentType
+			if contentType == "" {
+				contentType = fiber.MIMEApplicationJSON
+			}
+			item.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{contentType: {Schema: registry.SchemaOf(op.Request)}},
+			}
+		}
+
+		success := &Response{Description: "Success"}
+		if op.Response != nil {
+			success.Content = map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: registry.SchemaOf(op.Response)}}
+		}
+		item.Responses["200"] = success
+
+		errorContent := map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: errorSchema}}
+		if op.Request != nil {
+			item.Responses["400"] = &Response{Description: "Request tidak valid", Content: errorContent}
+			item.Responses["422"] = &Response{Description: "Validasi gagal", Content: errorContent}
+		}
+		if op.Auth {
+			item.Security = []map[string][]string{{"bearerAuth": {}}}
+			item.Responses["401"] = &Response{Description: "Tidak terautentikasi", Content: errorContent}
+		}
+		for status, description := range op.Responses {
+			item.Responses[fmt.Sprint(status)] = &Response{Description: description, Content: errorContent}
+		}
+		item.Responses["500"] = &Response{Description: "Kesalahan server", Content: errorContent}
+
+		if doc.Paths[openAPIPath] == nil {
+			doc.Paths[openAPIPath] = map[string]*PathItem{}
+		}
+		doc.Paths[openAPIPath][strings.ToLower(op.Method)] = item
+	}
+
+	doc.Components.Schemas = registry.components
+	return doc
+}
+
+// Missing mengembalikan route yang terdaftar di fiber tapi belum punya Operation,
+// dalam format "METHOD /path". Route HEAD otomatis dari GET dan path di ignore dilewati
+func Missing(routes []fiber.Route, operations []Operation, ignore ...string) []string {
+	documented := map[string]bool{}
+	for _, op := range operations {
+		documented[op.Method+" "+NormalizePath(op.Path)] = true
+	}
+
+	ignored := map[string]bool{}
+	for _, path := range ignore {
+		ignored[NormalizePath(path)] = true
+	}
+
+	seen := map[string]bool{}
+	missing := make([]string, 0)
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		path := NormalizePath(route.Path)
+		key := route.Method + " " + path
+		if ignored[path] || documented[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		missing = append(missing, key)
+	}
+
+	sort.Strings(missing)
+	return missing
+}
+
+// NormalizePath menyamakan path fiber, mis. "branch/" dan "/branch" menjadi "/branch"
+func NormalizePath(path string) string {
+	path = "/" + strings.Trim(path, "/")
+	return path
+}
+
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		segment = strings.TrimPrefix(strings.TrimSuffix(segment, "?"), ":")
+		if segment == "" {
+			continue
+		}
+		parts = append(parts, strings.ReplaceAll(segment, "-", "_"))
+	}
+	return strings.Join(parts, "_")
+}
+
+// Handler menyajikan dokumen sebagai JSON
+func Handler(doc *Document) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		return ctx.JSON(doc)
+	}
+}
+
+// SwaggerUI menyajikan halaman Swagger UI yang membaca spec dari specURL
+func SwaggerUI(title string, specURL string) fiber.Handler {
+	page := fmt.Sprintf(swaggerUITemplate, title, specURL)
+	return func(ctx *fiber.Ctx) error {
+		ctx.Type("html")
+		return ctx.SendString(page)
+	}
+}
+
+const swaggerUITemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>%s</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
@@ -0,0 +1,260 @@
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema adalah subset Schema Object OpenAPI 3.0 yang dipakai generator
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaRegistry mengubah tipe Go menjadi schema dan menyimpan struct sebagai components
+type schemaRegistry struct {
+	components map[string]*Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{components: map[string]*Schema{}}
+}
+
+// SchemaOf mengembalikan schema untuk value v; struct disimpan di components dan dirujuk lewat $ref
+func (r *schemaRegistry) SchemaOf(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+	return r.schemaOfType(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) schemaOfType(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	schema := r.baseSchema(t)
+	if nullable {
+		if schema.Ref != "" {
+			// OpenAPI 3.0 tidak membolehkan sibling di samping $ref, nullable dilewati
+			return schema
+		}
+		schema.Nullable = true
+	}
+	return schema
+}
+
+func (r *schemaRegistry) baseSchema(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		name := componentName(t)
+		if _, ok := r.components[name]; !ok {
+			// daftarkan dulu sebelum isi properti supaya struct rekursif tidak loop
+			r.components[name] = &Schema{}
+			r.components[name] = r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	// interface{} / any
+	return &Schema{}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		// embedded struct tanpa nama json: properti digabung ke parent
+		if field.Anonymous && name == field.Name {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := r.structSchema(embedded)
+				for k, v := range inner.Properties {
+					schema.Properties[k] = v
+				}
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+
+		property := r.schemaOfType(field.Type)
+		required := applyValidateTag(property, field.Tag.Get("validate"))
+		if doc := field.Tag.Get("doc"); doc != "" && property.Ref == "" {
+			property.Description = doc
+		}
+		if format := field.Tag.Get("format"); format != "" && property.Ref == "" {
+			property.Format = format
+		}
+
+		schema.Properties[name] = property
+
+		// field tanpa tag validate (response) selalu ada kecuali pointer atau omitempty
+		if required || (!omitEmpty && field.Type.Kind() != reflect.Pointer && field.Tag.Get("validate") == "") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+func jsonName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+var validateParam = regexp.MustCompile(`^([a-z_]+)(?:=(.*))?$`)
+
+// applyValidateTag menerjemahkan aturan validator yang umum ke constraint schema,
+// mengembalikan true jika field wajib diisi
+func applyValidateTag(schema *Schema, tag string) bool {
+	if tag == "" || schema.Ref != "" {
+		return strings.Contains(","+tag+",", ",required,")
+	}
+
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		match := validateParam.FindStringSubmatch(rule)
+		if match == nil {
+			continue
+		}
+		name, param := match[1], match[2]
+
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "max", "lte":
+			if n, err := strconv.Atoi(param); err == nil {
+				if schema.Type == "string" {
+					schema.MaxLength = &n
+				} else if schema.Type == "integer" || schema.Type == "number" {
+					f := float64(n)
+					schema.Maximum = &f
+				}
+			}
+		case "min", "gte":
+			if n, err := strconv.Atoi(param); err == nil {
+				if schema.Type == "string" {
+					schema.MinLength = &n
+				} else if schema.Type == "integer" || schema.Type == "number" {
+					f := float64(n)
+					schema.Minimum = &f
+				}
+			}
+		case "omitempty", "dive", "required_if", "required_unless", "required_with", "required_without":
+		default:
+			// aturan custom (branch_id, phone_id, ...) dicatat sebagai format
+			if schema.Type == "string" && schema.Format == "" {
+				schema.Format = name
+			}
+		}
+	}
+
+	return required
+}
+
+var genericArgs = regexp.MustCompile(`\[(.*)\]$`)
+
+// componentName membuat nama component yang stabil, termasuk untuk tipe generic:
+// utils.WebResponse[*model.CreateBranchResponse] -> WebResponse_CreateBranchResponse
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	base, _, isGeneric := strings.Cut(name, "[")
+	if !isGeneric {
+		return name
+	}
+
+	match := genericArgs.FindStringSubmatch(name)
+	if match == nil {
+		return base
+	}
+
+	args := strings.Split(match[1], ",")
+	parts := []string{base}
+	for _, arg := range args {
+		arg = strings.TrimLeft(strings.TrimSpace(arg), "*[]")
+		if idx := strings.LastIndex(arg, "."); idx != -1 {
+			arg = arg[idx+1:]
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, "_")
+}
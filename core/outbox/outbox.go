@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"backend/core/repo"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Event adalah baris tabel outbox_events. Event ditulis di transaksi yang sama dengan
+// perubahan data, lalu dikirim Relay ke handler setelah transaksi commit.
+// Pengiriman at-least-once: handler bisa menerima event yang sama lebih dari sekali,
+// pakai ID untuk deduplikasi
+type Event struct {
+	Seq           int64           `gorm:"column:seq;primaryKey;autoIncrement" json:"-"`
+	ID            string          `gorm:"column:id;size:36;not null;uniqueIndex" json:"id"`
+	Type          string          `gorm:"column:event_type;size:100;not null" json:"type"`
+	AggregateID   string          `gorm:"column:aggregate_id;size:100;not null;index" json:"aggregateId"`
+	Payload       json.RawMessage `gorm:"column:payload;type:json;not null" json:"payload"`
+	OccurredAt    time.Time       `gorm:"column:occurred_at;type:datetime(6);not null" json:"occurredAt"`
+	Attempts      int             `gorm:"column:attempts;not null;default:0" json:"-"`
+	NextAttemptAt time.Time       `gorm:"column:next_attempt_at;type:datetime(6);not null" json:"-"`
+	LockedUntil   *time.Time      `gorm:"column:locked_until;type:datetime(6)" json:"-"`
+	PublishedAt   *time.Time      `gorm:"column:published_at;type:datetime(6)" json:"-"`
+	FailedAt      *time.Time      `gorm:"column:failed_at;type:datetime(6)" json:"-"`
+	LastError     *string         `gorm:"column:last_error;type:text" json:"-"`
+}
+
+func (e *Event) TableName() string {
+	return "outbox_events"
+}
+
+// Record menulis event ke outbox memakai transaksi di db (atau di context db),
+// payload di-encode sebagai JSON
+func Record(db *gorm.DB, eventType, aggregateID string, payload any) error {
+	event, err := newEvent(uuid.NewString(), eventType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return repo.Conn(db).Create(event).Error
+}
+
+// RecordOnce sama seperti Record tapi ID event diturunkan dari key, jadi event dengan
+// key yang sama hanya ditulis sekali (mis. cabang expired per tanggal expire)
+func RecordOnce(db *gorm.DB, key, eventType, aggregateID string, payload any) error {
+	id := uuid.NewSHA1(uuid.NameSpaceOID, []byte(eventType+":"+key)).String()
+	event, err := newEvent(id, eventType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return repo.Conn(db).Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error
+}
+
+func newEvent(id, eventType, aggregateID string, payload any) (*Event, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Event{
+		ID:            id,
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Payload:       raw,
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}, nil
+}
@@ -0,0 +1,214 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Handler memproses satu event. Error membuat event dikirim ulang ke semua handler,
+// jadi handler harus idempotent
+type Handler func(ctx context.Context, event *Event) error
+
+// AllEvents dipakai di Subscribe untuk menerima semua tipe event
+const AllEvents = "*"
+
+// RelayConfig mengatur Relay. Event yang gagal dicoba ulang dengan backoff eksponensial
+// (RetryDelay, 2x, 4x, ... maksimal MaxRetryDelay) sampai MaxAttempts lalu ditandai gagal
+type RelayConfig struct {
+	Interval      time.Duration
+	BatchSize     int
+	MaxAttempts   int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// Lease adalah lama event di-klaim satu proses, setelah itu boleh diambil proses lain
+	Lease time.Duration
+}
+
+// Relay membaca event yang belum terkirim dari outbox_events dan mengirimkannya ke
+// handler yang terdaftar. Aman dijalankan di beberapa proses (prefork atau beberapa
+// container) karena event di-klaim dengan SELECT ... FOR UPDATE SKIP LOCKED
+type Relay struct {
+	DB     *gorm.DB
+	Log    *logrus.Logger
+	Config RelayConfig
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewRelay(db *gorm.DB, log *logrus.Logger, config RelayConfig) *Relay {
+	return &Relay{
+		DB:       db,
+		Log:      log,
+		Config:   config,
+		handlers: map[string][]Handler{},
+	}
+}
+
+// Subscribe mendaftarkan handler untuk tipe event, AllEvents untuk semua tipe
+func (r *Relay) Subscribe(eventType string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[eventType] = append(r.handlers[eventType], handler)
+}
+
+// Start menjalankan relay di background sampai ctx selesai
+func (r *Relay) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.Config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// kirim terus selama batch penuh supaya antrian panjang cepat habis
+			for {
+				sent, err := r.RelayOnce(ctx)
+				if err != nil {
+					r.Log.Warnf("Failed to relay outbox events : %+v", err)
+				}
+				if err != nil || sent < r.Config.BatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
+
+// RelayOnce mengklaim satu batch event lalu mengirimkannya, mengembalikan jumlah event yang diproses
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		r.dispatch(ctx, event)
+	}
+	return len(events), nil
+}
+
+func (r *Relay) claim(ctx context.Context) ([]*Event, error) {
+	events := make([]*Event, 0)
+	now := time.Now()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+			Where("published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", now).
+			Where("locked_until IS NULL OR locked_until <= ?", now).
+			Order("seq").
+			Limit(r.Config.BatchSize).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		seqs := make([]int64, 0, len(events))
+		for _, event := range events {
+			seqs = append(seqs, event.Seq)
+		}
+		return tx.Model(new(Event)).Where("seq IN ?", seqs).Update("locked_until", now.Add(r.Config.Lease)).Error
+	})
+	return events, err
+}
+
+func (r *Relay) dispatch(ctx context.Context, event *Event) {
+	r.mu.RLock()
+	handlers := append(append([]Handler{}, r.handlers[event.Type]...), r.handlers[AllEvents]...)
+	r.mu.RUnlock()
+
+	var failure error
+	for _, handler := range handlers {
+		if err := safeHandle(ctx, handler, event); err != nil {
+			failure = err
+			break
+		}
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"locked_until": nil}
+	if failure == nil {
+		updates["published_at"] = now
+	} else {
+		attempts := event.Attempts + 1
+		message := failure.Error()
+		updates["attempts"] = attempts
+		updates["last_error"] = message
+		if attempts >= r.Config.MaxAttempts {
+			updates["failed_at"] = now
+			r.Log.WithContext(ctx).Errorf("Outbox event %s (%s) failed after %d attempts : %s", event.ID, event.Type, attempts, message)
+		} else {
+			updates["next_attempt_at"] = now.Add(Backoff(attempts, r.Config.RetryDelay, r.Config.MaxRetryDelay))
+			r.Log.WithContext(ctx).Warnf("Outbox event %s (%s) failed, attempt %d : %s", event.ID, event.Type, attempts, message)
+		}
+	}
+
+	if err := r.DB.WithContext(ctx).Model(new(Event)).Where("seq = ?", event.Seq).Updates(updates).Error; err != nil {
+		r.Log.WithContext(ctx).Warnf("Failed to update outbox event %s : %+v", event.ID, err)
+	}
+}
+
+// safeHandle mengubah panic di handler menjadi error supaya relay tetap berjalan
+func safeHandle(ctx context.Context, handler Handler, event *Event) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// Backoff menghitung jeda sebelum percobaan ke-attempt berikutnya: delay * 2^(attempt-1), maksimal max
+func Backoff(attempt int, delay, max time.Duration) time.Duration {
+	backoff := delay
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// Purge menghapus event yang sudah terkirim sebelum olderThan
+func (r *Relay) Purge(ctx context.Context, olderThan time.Time) error {
+	return r.DB.WithContext(ctx).Where("published_at IS NOT NULL AND published_at < ?", olderThan).Delete(new(Event)).Error
+}
+
+// StartPurge menghapus event terkirim yang lebih lama dari retention secara berkala di background
+func StartPurge(relay *Relay, interval, retention time.Duration, log *logrus.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if err := relay.Purge(ctx, time.Now().Add(-retention)); err != nil {
+				log.Warnf("Failed to purge outbox events : %+v", err)
+			}
+			cancel()
+		}
+	}()
+}
+
+// LogHandler mencatat setiap event ke log (audit sederhana)
+func LogHandler(log *logrus.Logger) Handler {
+	return func(ctx context.Context, event *Event) error {
+		log.WithContext(ctx).WithFields(logrus.Fields{
+			"event_id":     event.ID,
+			"event_type":   event.Type,
+			"aggregate_id": event.AggregateID,
+		}).Info("Domain event published")
+		return nil
+	}
+}
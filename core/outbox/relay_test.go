@@ -0,0 +1,253 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// DDL ditulis manual karena driver SQLite tidak membaca kolom datetime(6) sebagai time
+const outboxTestTable = `CREATE TABLE outbox_events (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	id TEXT NOT NULL UNIQUE,
+	event_type TEXT NOT NULL,
+	aggregate_id TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	occurred_at DATETIME NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at DATETIME NOT NULL,
+	locked_until DATETIME,
+	published_at DATETIME,
+	failed_at DATETIME,
+	last_error TEXT
+)`
+
+func newTestRelay(t *testing.T, config RelayConfig) *Relay {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.Exec(outboxTestTable).Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewRelay(db, log, config)
+}
+
+func findEvents(t *testing.T, r *Relay) []Event {
+	t.Helper()
+	var events []Event
+	if err := r.DB.Order("seq").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestRelayOnceDispatch(t *testing.T) {
+	r := newTestRelay(t, RelayConfig{BatchSize: 10, MaxAttempts: 3, RetryDelay: time.Minute, MaxRetryDelay: time.Hour, Lease: time.Minute})
+	for _, eventType := range []string{"branch.created", "branch.updated"} {
+		if err := Record(r.DB, eventType, "A1", map[string]string{"id": "A1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var created, all []string
+	r.Subscribe("branch.created", func(ctx context.Context, event *Event) error {
+		created = append(created, event.Type)
+		return nil
+	})
+	r.Subscribe(AllEvents, func(ctx context.Context, event *Event) error {
+		all = append(all, event.Type)
+		return nil
+	})
+
+	sent, err := r.RelayOnce(context.Background())
+	if err != nil || sent != 2 {
+		t.Fatalf("RelayOnce = %d, %v; want 2", sent, err)
+	}
+	if fmt.Sprint(created) != "[branch.created]" || fmt.Sprint(all) != "[branch.created branch.updated]" {
+		t.Fatalf("handlers got %v and %v", created, all)
+	}
+	for _, event := range findEvents(t, r) {
+		if event.PublishedAt == nil || event.LockedUntil != nil || event.Attempts != 0 {
+			t.Fatalf("event %+v, want published and unlocked", event)
+		}
+	}
+
+	// event terkirim tidak diambil lagi
+	if sent, err := r.RelayOnce(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("second RelayOnce = %d, %v; want 0", sent, err)
+	}
+}
+
+func TestClaimSkipLockedAndLease(t *testing.T) {
+	r := newTestRelay(t, RelayConfig{BatchSize: 2, MaxAttempts: 3, RetryDelay: time.Minute, MaxRetryDelay: time.Hour, Lease: time.Minute})
+	for i := 0; i < 3; i++ {
+		if err := Record(r.DB, "branch.updated", fmt.Sprintf("A%d", i), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// SQLite tidak menulis FOR UPDATE ke SQL, jadi cek clause yang dipasang di query klaim
+	var locking clause.Locking
+	r.DB.Callback().Query().Before("gorm:query").Register("test:capture_locking", func(db *gorm.DB) {
+		if c, ok := db.Statement.Clauses["FOR"]; ok {
+			locking, _ = c.Expression.(clause.Locking)
+		}
+	})
+
+	ctx := context.Background()
+	first, err := r.claim(ctx)
+	if err != nil || len(first) != 2 {
+		t.Fatalf("claim = %d, %v; want 2 (batch size)", len(first), err)
+	}
+	if locking.Strength != clause.LockingStrengthUpdate || locking.Options != clause.LockingOptionsSkipLocked {
+		t.Fatalf("locking %+v, want FOR UPDATE SKIP LOCKED", locking)
+	}
+	if first[0].Seq != 1 || first[1].Seq != 2 {
+		t.Fatalf("claimed seq %d, %d; want 1, 2 in order", first[0].Seq, first[1].Seq)
+	}
+
+	// event yang sedang di-klaim dilewati proses lain
+	second, err := r.claim(ctx)
+	if err != nil || len(second) != 1 || second[0].Seq != 3 {
+		t.Fatalf("second claim = %+v, %v; want only seq 3", second, err)
+	}
+	if third, err := r.claim(ctx); err != nil || len(third) != 0 {
+		t.Fatalf("third claim = %d, %v; want 0 while leased", len(third), err)
+	}
+
+	// lease habis (proses yang mengklaim mati), event boleh diambil lagi
+	if err := r.DB.Model(new(Event)).Where("seq IN ?", []int64{1, 2}).Update("locked_until", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+	again, err := r.claim(ctx)
+	if err != nil || len(again) != 2 {
+		t.Fatalf("claim after lease = %d, %v; want 2", len(again), err)
+	}
+}
+
+func TestRelayBackoffThenFailed(t *testing.T) {
+	config := RelayConfig{BatchSize: 10, MaxAttempts: 3, RetryDelay: time.Minute, MaxRetryDelay: 90 * time.Second, Lease: time.Minute}
+	r := newTestRelay(t, config)
+	if err := Record(r.DB, "branch.updated", "A1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	r.Subscribe(AllEvents, func(ctx context.Context, event *Event) error {
+		calls++
+		// panic di handler dicatat sebagai kegagalan biasa
+		if calls == 2 {
+			panic("boom")
+		}
+		return errors.New("receiver down")
+	})
+	ctx := context.Background()
+
+	// backoff eksponensial dibatasi MaxRetryDelay: 1m lalu 1m30s (bukan 2m)
+	for attempt, backoff := range []time.Duration{time.Minute, 90 * time.Second} {
+		before := time.Now()
+		if sent, err := r.RelayOnce(ctx); err != nil || sent != 1 {
+			t.Fatalf("attempt %d: RelayOnce = %d, %v; want 1", attempt+1, sent, err)
+		}
+
+		event := findEvents(t, r)[0]
+		if event.Attempts != attempt+1 || event.PublishedAt != nil || event.FailedAt != nil || event.LockedUntil != nil || event.LastError == nil {
+			t.Fatalf("attempt %d: event %+v, want pending retry", attempt+1, event)
+		}
+		if delay := event.NextAttemptAt.Sub(before); delay < backoff || delay > backoff+5*time.Second {
+			t.Fatalf("attempt %d: next attempt in %s, want about %s", attempt+1, delay, backoff)
+		}
+
+		// belum jadwalnya, tidak diambil lagi
+		if sent, err := r.RelayOnce(ctx); err != nil || sent != 0 {
+			t.Fatalf("attempt %d: RelayOnce before schedule = %d, %v; want 0", attempt+1, sent, err)
+		}
+		if err := r.DB.Model(new(Event)).Where("seq = ?", event.Seq).Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if sent, err := r.RelayOnce(ctx); err != nil || sent != 1 {
+		t.Fatalf("last attempt: RelayOnce = %d, %v; want 1", sent, err)
+	}
+	event := findEvents(t, r)[0]
+	if event.FailedAt == nil || event.Attempts != config.MaxAttempts || event.PublishedAt != nil {
+		t.Fatalf("event %+v, want failed after %d attempts", event, config.MaxAttempts)
+	}
+
+	// event gagal tidak dikirim lagi
+	if sent, err := r.RelayOnce(ctx); err != nil || sent != 0 {
+		t.Fatalf("after failed: RelayOnce = %d, %v; want 0", sent, err)
+	}
+	if calls != config.MaxAttempts {
+		t.Fatalf("handler called %d times, want %d", calls, config.MaxAttempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{10, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt, time.Second, 10*time.Second); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRecordOnce(t *testing.T) {
+	r := newTestRelay(t, RelayConfig{})
+
+	// key yang sama hanya ditulis sekali, key lain menjadi event baru
+	for _, key := range []string{"A1:2030-01-01", "A1:2030-01-01", "A1:2031-01-01"} {
+		if err := RecordOnce(r.DB, key, "branch.expired", "A1", map[string]string{"id": "A1"}); err != nil {
+			t.Fatalf("RecordOnce(%s): %v", key, err)
+		}
+	}
+
+	events := findEvents(t, r)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].ID == events[1].ID {
+		t.Fatalf("events share id %s", events[0].ID)
+	}
+
+	// key sama dengan tipe event berbeda menjadi event terpisah
+	if err := RecordOnce(r.DB, "A1:2030-01-01", "branch.renewed", "A1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if events := findEvents(t, r); len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+}
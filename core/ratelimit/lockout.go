@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"backend/core/utils"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Guard mengunci subject (mis. nomor telepon saat verifikasi OTP) setelah gagal
+// berulang kali. Service memanggil Check sebelum memproses, Fail saat percobaan gagal
+// dan Reset saat berhasil
+type Guard struct {
+	Store  Store
+	Prefix string
+	Policy func() LockoutPolicy
+}
+
+func NewGuard(store Store, prefix string, policy func() LockoutPolicy) *Guard {
+	return &Guard{Store: store, Prefix: prefix, Policy: policy}
+}
+
+// Check mengembalikan AppError 429 jika subject masih terkunci
+func (g *Guard) Check(ctx context.Context, subject string) error {
+	if g.Policy().MaxAttempts == 0 {
+		return nil
+	}
+
+	lockedUntil, err := g.Store.LockedUntil(ctx, g.key(subject))
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(lockedUntil) {
+		return utils.NewAppError(fiber.StatusTooManyRequests, utils.ErrCodeTooManyAttempts, "")
+	}
+	return nil
+}
+
+// Fail mencatat satu percobaan gagal, mengembalikan AppError 429 jika subject jadi terkunci
+func (g *Guard) Fail(ctx context.Context, subject string) error {
+	policy := g.Policy()
+	if policy.MaxAttempts == 0 {
+		return nil
+	}
+
+	lockedUntil, err := g.Store.RegisterFailure(ctx, g.key(subject), policy)
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(lockedUntil) {
+		return utils.NewAppError(fiber.StatusTooManyRequests, utils.ErrCodeTooManyAttempts, "")
+	}
+	return nil
+}
+
+// Reset menghapus catatan kegagalan subject
+func (g *Guard) Reset(ctx context.Context, subject string) error {
+	return g.Store.ResetFailures(ctx, g.key(subject))
+}
+
+func (g *Guard) key(subject string) string {
+	return g.Prefix + ":" + subject
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+type memoryLockout struct {
+	failures    int
+	windowEnds  time.Time
+	lockedUntil time.Time
+}
+
+// MemoryStore menyimpan bucket di memory proses. Tidak berbagi state antar proses
+// prefork maupun antar container, pakai MySQLStore untuk itu
+type MemoryStore struct {
+	mu       sync.Mutex
+	buckets  map[string]*memoryBucket
+	lockouts map[string]*memoryLockout
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  map[string]*memoryBucket{},
+		lockouts: map[string]*memoryLockout{},
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: limit.capacity(), updatedAt: now}
+		s.buckets[key] = bucket
+	}
+
+	tokens, result := take(bucket.tokens, bucket.updatedAt, now, limit)
+	bucket.tokens = tokens
+	bucket.updatedAt = now
+	bucket.expiresAt = now.Add(result.Reset)
+	return result, nil
+}
+
+func (s *MemoryStore) RegisterFailure(ctx context.Context, key string, policy LockoutPolicy) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	lockout, ok := s.lockouts[key]
+	if !ok || now.After(lockout.windowEnds) {
+		lockout = &memoryLockout{windowEnds: now.Add(time.Duration(policy.Window) * time.Second)}
+		s.lockouts[key] = lockout
+	}
+
+	lockout.failures++
+	if lockout.failures >= policy.MaxAttempts {
+		lockout.lockedUntil = now.Add(time.Duration(policy.Duration) * time.Second)
+		lockout.failures = 0
+	}
+	return lockout.lockedUntil, nil
+}
+
+func (s *MemoryStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lockout, ok := s.lockouts[key]
+	if !ok || time.Now().After(lockout.lockedUntil) {
+		return time.Time{}, nil
+	}
+	return lockout.lockedUntil, nil
+}
+
+func (s *MemoryStore) ResetFailures(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.lockouts, key)
+	return nil
+}
+
+func (s *MemoryStore) Purge(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, bucket := range s.buckets {
+		if now.After(bucket.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, lockout := range s.lockouts {
+		if now.After(lockout.windowEnds) && now.After(lockout.lockedUntil) {
+			delete(s.lockouts, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Bucket adalah baris token bucket di tabel rate_limit_buckets
+type Bucket struct {
+	Key       string    `gorm:"column:key;primaryKey;size:191"`
+	Tokens    float64   `gorm:"column:tokens;not null"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime(6);not null;autoUpdateTime:false"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:datetime(6);not null;index"`
+}
+
+func (b *Bucket) TableName() string {
+	return "rate_limit_buckets"
+}
+
+// Lockout adalah baris counter kegagalan di tabel rate_limit_lockouts
+type Lockout struct {
+	Key         string     `gorm:"column:key;primaryKey;size:191"`
+	Failures    int        `gorm:"column:failures;not null;default:0"`
+	WindowEnds  time.Time  `gorm:"column:window_ends;type:datetime(6);not null;index"`
+	LockedUntil *time.Time `gorm:"column:locked_until;type:datetime(6)"`
+}
+
+func (l *Lockout) TableName() string {
+	return "rate_limit_lockouts"
+}
+
+// MySQLStore menyimpan bucket di MySQL sehingga batas tetap benar walau aplikasi
+// berjalan dengan prefork atau beberapa container. Setiap Take mengunci baris bucket
+// dengan SELECT ... FOR UPDATE di dalam transaksi
+type MySQLStore struct {
+	DB *gorm.DB
+}
+
+func NewMySQLStore(db *gorm.DB) *MySQLStore {
+	return &MySQLStore{DB: db}
+}
+
+func (s *MySQLStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	var result Result
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// bucket baru dibuat penuh; IGNORE supaya proses lain yang membuat lebih dulu tidak error
+		initial := &Bucket{Key: key, Tokens: limit.capacity(), UpdatedAt: now, ExpiresAt: now}
+		if err := tx.Clauses(clause.Insert{Modifier: "IGNORE"}).Create(initial).Error; err != nil {
+			return err
+		}
+
+		bucket := new(Bucket)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("`key` = ?", key).Take(bucket).Error; err != nil {
+			return err
+		}
+
+		var tokens float64
+		tokens, result = take(bucket.Tokens, bucket.UpdatedAt, now, limit)
+
+		return tx.Model(bucket).Updates(map[string]interface{}{
+			"tokens":     tokens,
+			"updated_at": now,
+			"expires_at": now.Add(result.Reset),
+		}).Error
+	})
+
+	return result, err
+}
+
+func (s *MySQLStore) RegisterFailure(ctx context.Context, key string, policy LockoutPolicy) (time.Time, error) {
+	var lockedUntil time.Time
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		windowEnds := now.Add(time.Duration(policy.Window) * time.Second)
+
+		initial := &Lockout{Key: key, WindowEnds: windowEnds}
+		if err := tx.Clauses(clause.Insert{Modifier: "IGNORE"}).Create(initial).Error; err != nil {
+			return err
+		}
+
+		lockout := new(Lockout)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("`key` = ?", key).Take(lockout).Error; err != nil {
+			return err
+		}
+
+		// window lama sudah lewat, mulai hitung dari awal
+		if now.After(lockout.WindowEnds) {
+			lockout.Failures = 0
+			lockout.WindowEnds = windowEnds
+		}
+
+		lockout.Failures++
+		if lockout.Failures >= policy.MaxAttempts {
+			until := now.Add(time.Duration(policy.Duration) * time.Second)
+			lockout.LockedUntil = &until
+			lockout.Failures = 0
+		}
+		if lockout.LockedUntil != nil {
+			lockedUntil = *lockout.LockedUntil
+		}
+
+		return tx.Model(lockout).Updates(map[string]interface{}{
+			"failures":     lockout.Failures,
+			"window_ends":  lockout.WindowEnds,
+			"locked_until": lockout.LockedUntil,
+		}).Error
+	})
+
+	return lockedUntil, err
+}
+
+func (s *MySQLStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	var lockouts []Lockout
+	err := s.DB.WithContext(ctx).
+		Where("`key` = ? AND locked_until > ?", key, time.Now()).
+		Limit(1).
+		Find(&lockouts).Error
+	if err != nil || len(lockouts) == 0 {
+		return time.Time{}, err
+	}
+	return *lockouts[0].LockedUntil, nil
+}
+
+func (s *MySQLStore) ResetFailures(ctx context.Context, key string) error {
+	return s.DB.WithContext(ctx).Where("`key` = ?", key).Delete(new(Lockout)).Error
+}
+
+func (s *MySQLStore) Purge(ctx context.Context) error {
+	now := time.Now()
+	db := s.DB.WithContext(ctx)
+
+	if err := db.Where("expires_at <= ?", now).Delete(new(Bucket)).Error; err != nil {
+		return err
+	}
+	return db.Where("window_ends <= ? AND (locked_until IS NULL OR locked_until <= ?)", now, now).Delete(new(Lockout)).Error
+}
@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"backend/core/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestTakeBurst(t *testing.T) {
+	// 60 request per menit dengan burst 3: tiga request beruntun lolos, keempat ditolak
+	limit := Limit{Requests: 60, Period: 60, Burst: 3}
+	now := time.Now()
+	tokens := limit.capacity()
+
+	for i := 0; i < 3; i++ {
+		var result Result
+		tokens, result = take(tokens, now, now, limit)
+		if !result.Allowed || result.Limit != 3 || result.Remaining != 2-i {
+			t.Fatalf("request %d: %+v, want allowed with %d remaining", i+1, result, 2-i)
+		}
+	}
+
+	_, result := take(tokens, now, now, limit)
+	if result.Allowed || result.RetryAfter != time.Second {
+		t.Fatalf("over burst: %+v, want denied with retry after 1s", result)
+	}
+	if result.Reset != 3*time.Second {
+		t.Fatalf("reset %s, want 3s until bucket full", result.Reset)
+	}
+}
+
+func TestTakeRefill(t *testing.T) {
+	limit := Limit{Requests: 10, Period: 60, Burst: 2}
+	now := time.Now()
+
+	// bucket kosong terisi 1 token setiap 6 detik
+	if _, result := take(0, now, now.Add(3*time.Second), limit); result.Allowed {
+		t.Fatalf("after 3s: %+v, want denied", result)
+	}
+	tokens, result := take(0, now, now.Add(6*time.Second), limit)
+	if !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("after 6s: %+v, want allowed with 0 remaining", result)
+	}
+	if tokens != 0 {
+		t.Fatalf("tokens %v, want 0", tokens)
+	}
+
+	// pengisian tidak melebihi kapasitas burst
+	tokens, result = take(0, now, now.Add(time.Hour), limit)
+	if !result.Allowed || tokens != 1 {
+		t.Fatalf("after 1h: tokens %v %+v, want 1 left of burst 2", tokens, result)
+	}
+}
+
+func TestTakeWithoutBurst(t *testing.T) {
+	// Burst 0 berarti kapasitas sama dengan Requests
+	limit := Limit{Requests: 5, Period: 60}
+	if got := limit.capacity(); got != 5 {
+		t.Fatalf("capacity %v, want 5", got)
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Period: 3600}
+
+	for i := 0; i < 2; i++ {
+		if result, err := store.Take(ctx, "a", limit); err != nil || !result.Allowed {
+			t.Fatalf("take %d: %+v, %v; want allowed", i+1, result, err)
+		}
+	}
+	if result, err := store.Take(ctx, "a", limit); err != nil || result.Allowed {
+		t.Fatalf("take 3: %+v, %v; want denied", result, err)
+	}
+	// bucket terpisah per key
+	if result, err := store.Take(ctx, "b", limit); err != nil || !result.Allowed {
+		t.Fatalf("other key: %+v, %v; want allowed", result, err)
+	}
+}
+
+func tooManyAttempts(err error) bool {
+	var appErr *utils.AppError
+	return errors.As(err, &appErr) && appErr.Status == fiber.StatusTooManyRequests && appErr.Code == utils.ErrCodeTooManyAttempts
+}
+
+func TestGuardLockout(t *testing.T) {
+	policy := LockoutPolicy{MaxAttempts: 3, Window: 900, Duration: 900}
+	guard := NewGuard(NewMemoryStore(), "otp", func() LockoutPolicy { return policy })
+	ctx := context.Background()
+	phone := "+628123456789"
+
+	for i := 0; i < 2; i++ {
+		if err := guard.Fail(ctx, phone); err != nil {
+			t.Fatalf("failure %d: %v, want nil", i+1, err)
+		}
+	}
+	if err := guard.Check(ctx, phone); err != nil {
+		t.Fatalf("Check before limit: %v", err)
+	}
+
+	if err := guard.Fail(ctx, phone); !tooManyAttempts(err) {
+		t.Fatalf("failure 3: %v, want TOO_MANY_ATTEMPTS", err)
+	}
+	if err := guard.Check(ctx, phone); !tooManyAttempts(err) {
+		t.Fatalf("Check while locked: %v, want TOO_MANY_ATTEMPTS", err)
+	}
+	// lockout per nomor telepon, nomor lain tidak ikut terkunci
+	if err := guard.Check(ctx, "+628000000000"); err != nil {
+		t.Fatalf("Check other phone: %v", err)
+	}
+
+	if err := guard.Reset(ctx, phone); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(ctx, phone); err != nil {
+		t.Fatalf("Check after reset: %v", err)
+	}
+}
+
+func TestGuardWindowExpired(t *testing.T) {
+	store := NewMemoryStore()
+	policy := LockoutPolicy{MaxAttempts: 2, Window: 900, Duration: 900}
+	guard := NewGuard(store, "otp", func() LockoutPolicy { return policy })
+	ctx := context.Background()
+
+	if err := guard.Fail(ctx, "phone"); err != nil {
+		t.Fatal(err)
+	}
+	// window kegagalan pertama sudah lewat, hitungan mulai dari awal
+	store.lockouts[guard.key("phone")].windowEnds = time.Now().Add(-time.Second)
+	if err := guard.Fail(ctx, "phone"); err != nil {
+		t.Fatalf("failure after window: %v, want nil", err)
+	}
+}
+
+func TestGuardDisabled(t *testing.T) {
+	guard := NewGuard(NewMemoryStore(), "otp", func() LockoutPolicy { return LockoutPolicy{} })
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		if err := guard.Fail(ctx, "phone"); err != nil {
+			t.Fatalf("failure %d: %v, want nil when MaxAttempts 0", i+1, err)
+		}
+	}
+	if err := guard.Check(ctx, "phone"); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+}
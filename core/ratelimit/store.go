@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Limit adalah aturan token bucket: Requests token terisi setiap Period detik,
+// maksimal Burst token (default sama dengan Requests). Requests 0 berarti tanpa batas
+type Limit struct {
+	Requests int `mapstructure:"requests" validate:"min=0"`
+	Period   int `mapstructure:"period" validate:"required_with=Requests,min=0"`
+	Burst    int `mapstructure:"burst" validate:"min=0"`
+}
+
+// Rule adalah batas untuk satu route group, per IP dan per user yang login
+type Rule struct {
+	IP   Limit `mapstructure:"ip"`
+	User Limit `mapstructure:"user"`
+}
+
+// LockoutPolicy mengunci subject (mis. nomor telepon) selama Duration detik setelah
+// MaxAttempts kali gagal dalam Window detik. MaxAttempts 0 berarti lockout nonaktif
+type LockoutPolicy struct {
+	MaxAttempts int `mapstructure:"maxAttempts" validate:"min=0"`
+	Window      int `mapstructure:"window" validate:"required_with=MaxAttempts,min=0"`
+	Duration    int `mapstructure:"duration" validate:"required_with=MaxAttempts,min=0"`
+}
+
+// Result adalah hasil pengambilan satu token, dipakai untuk header RateLimit-*
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store adalah backend penyimpanan bucket dan counter lockout (memory atau MySQL)
+type Store interface {
+	// Take mengambil satu token dari bucket key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// RegisterFailure mencatat satu kegagalan dan mengembalikan akhir lockout jika batas terlampaui
+	RegisterFailure(ctx context.Context, key string, policy LockoutPolicy) (time.Time, error)
+	// LockedUntil mengembalikan akhir lockout key, zero time jika tidak terkunci
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// ResetFailures menghapus counter kegagalan, dipakai setelah percobaan berhasil
+	ResetFailures(ctx context.Context, key string) error
+	// Purge menghapus bucket penuh dan lockout yang sudah lewat
+	Purge(ctx context.Context) error
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// rate adalah jumlah token yang terisi per detik
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / float64(l.Period)
+}
+
+// take mengisi ulang bucket sesuai waktu yang berlalu lalu mengambil satu token,
+// mengembalikan sisa token dan hasilnya
+func take(tokens float64, updatedAt, now time.Time, limit Limit) (float64, Result) {
+	capacity := limit.capacity()
+	rate := limit.rate()
+
+	elapsed := now.Sub(updatedAt).Seconds()
+	if elapsed > 0 {
+		tokens = math.Min(capacity, tokens+elapsed*rate)
+	}
+
+	result := Result{Limit: int(capacity)}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = seconds((capacity - tokens) / rate)
+	return tokens, result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
+
+// StartPurge menghapus bucket dan lockout yang sudah tidak dipakai secara berkala di background
+func StartPurge(store Store, interval time.Duration, log *logrus.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if err := store.Purge(ctx); err != nil {
+				log.Warnf("Failed to purge rate limit buckets : %+v", err)
+			}
+			cancel()
+		}
+	}()
+}
@@ -0,0 +1,287 @@
+package repo
+
+import (
+	"backend/core/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// SortColumn adalah satu kolom urutan, Desc untuk descending
+type SortColumn struct {
+	Name string
+	Desc bool
+}
+
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+// cursorToken adalah isi cursor sebelum di-encode base64. Sort menyimpan hash urutan
+// supaya cursor dari urutan lain ditolak, bukan menghasilkan halaman yang salah
+type cursorToken struct {
+	Direction string    `json:"d"`
+	Sort      string    `json:"s"`
+	Values    []*string `json:"v"`
+}
+
+// FindWithCursor: pagination keyset tanpa COUNT dan OFFSET. cursor kosong berarti halaman
+// pertama, selain itu cursor next/prev dari pemanggilan sebelumnya. Primary key otomatis
+// ditambahkan ke sort supaya urutan unik. NULL dianggap paling kecil seperti ORDER BY MySQL
+// (di awal untuk ascending, di akhir untuk descending)
+func (r *Repository[T]) FindWithCursor(
+	db *gorm.DB,
+	out *[]T,
+	cursor string,
+	limit int,
+	sort []SortColumn,
+	opts ...QueryOption,
+) (next string, prev string, err error) {
+	s, err := schema.Parse(new(T), schemaCache, db.NamingStrategy)
+	if err != nil {
+		return "", "", err
+	}
+	sort = withPrimaryKey(s, sort)
+	fingerprint := sortFingerprint(sort)
+
+	direction := cursorNext
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
+	}
+
+	if cursor != "" {
+		token, err := decodeCursor(cursor, fingerprint, len(sort))
+		if err != nil {
+			return "", "", err
+		}
+		direction = token.Direction
+
+		values, err := cursorValues(s, sort, token.Values)
+		if err != nil {
+			return "", "", err
+		}
+		query = query.Where(keysetCondition(s, sort, values, direction == cursorPrev))
+	}
+
+	// halaman sebelumnya diambil dengan urutan terbalik lalu dibalik lagi
+	for _, column := range sort {
+		desc := column.Desc
+		if direction == cursorPrev {
+			desc = !desc
+		}
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: column.Name}, Desc: desc})
+	}
+
+	// ambil satu baris lebih untuk tahu masih ada halaman berikutnya atau tidak
+	if err := query.Limit(limit + 1).Find(out).Error; err != nil {
+		return "", "", err
+	}
+
+	hasMore := len(*out) > limit
+	if hasMore {
+		*out = (*out)[:limit]
+	}
+	if direction == cursorPrev {
+		reverse(*out)
+	}
+	if len(*out) == 0 {
+		return "", "", nil
+	}
+
+	hasNext := hasMore
+	hasPrev := cursor != ""
+	if direction == cursorPrev {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	ctx := db.Statement.Context
+	if hasNext {
+		if next, err = encodeCursor(ctx, s, sort, fingerprint, cursorNext, &(*out)[len(*out)-1]); err != nil {
+			return "", "", err
+		}
+	}
+	if hasPrev {
+		if prev, err = encodeCursor(ctx, s, sort, fingerprint, cursorPrev, &(*out)[0]); err != nil {
+			return "", "", err
+		}
+	}
+	return next, prev, nil
+}
+
+// withPrimaryKey menambahkan primary key yang belum ada di sort sebagai tie breaker
+func withPrimaryKey(s *schema.Schema, sort []SortColumn) []SortColumn {
+	result := append([]SortColumn{}, sort...)
+	for _, field := range s.PrimaryFields {
+		found := false
+		for _, column := range sort {
+			if column.Name == field.DBName {
+				found = true
+			}
+		}
+		if !found {
+			result = append(result, SortColumn{Name: field.DBName})
+		}
+	}
+	return result
+}
+
+func sortFingerprint(sort []SortColumn) string {
+	parts := make([]string, 0, len(sort))
+	for _, column := range sort {
+		if column.Desc {
+			parts = append(parts, "-"+column.Name)
+		} else {
+			parts = append(parts, column.Name)
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, ",")))
+	return hex.EncodeToString(sum[:4])
+}
+
+// keysetCondition membuat (a > ?) OR (a = ? AND b > ?) OR ... sesuai arah tiap kolom.
+// Untuk kolom nullable, NULL ditempatkan sebelum semua nilai lain
+func keysetCondition(s *schema.Schema, sort []SortColumn, values []any, backward bool) clause.Expression {
+	ors := make([]clause.Expression, 0, len(sort))
+	for i, column := range sort {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			// clause.Eq dengan nilai nil ditulis sebagai IS NULL
+			ands = append(ands, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sort[j].Name}, Value: values[j]})
+		}
+
+		after, ok := afterValue(column.Name, nullable(s, column.Name), values[i], column.Desc != backward)
+		if !ok {
+			continue
+		}
+		ors = append(ors, clause.And(append(ands, after)...))
+	}
+	if len(ors) == 0 {
+		return clause.Expr{SQL: "1 = 0"}
+	}
+	return clause.Or(ors...)
+}
+
+// afterValue membuat kondisi baris yang berada setelah value pada satu kolom,
+// ok false jika tidak ada nilai setelahnya (NULL saat urutan menurun)
+func afterValue(name string, nullable bool, value any, less bool) (clause.Expression, bool) {
+	col := clause.Column{Table: clause.CurrentTable, Name: name}
+	switch {
+	case less && value == nil:
+		return nil, false
+	case less && nullable:
+		return clause.Or(clause.Lt{Column: col, Value: value}, clause.Expr{SQL: "? IS NULL", Vars: []any{col}}), true
+	case less:
+		return clause.Lt{Column: col, Value: value}, true
+	case value == nil:
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []any{col}}, true
+	default:
+		return clause.Gt{Column: col, Value: value}, true
+	}
+}
+
+func nullable(s *schema.Schema, name string) bool {
+	field := s.LookUpField(name)
+	return field != nil && !field.NotNull && !field.PrimaryKey
+}
+
+func encodeCursor(ctx context.Context, s *schema.Schema, sort []SortColumn, fingerprint string, direction string, item any) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	row := reflect.ValueOf(item).Elem()
+
+	token := cursorToken{Direction: direction, Sort: fingerprint, Values: make([]*string, 0, len(sort))}
+	for _, column := range sort {
+		field := s.LookUpField(column.Name)
+		value, zero := field.ValueOf(ctx, row)
+		value = dereference(value)
+		if value == nil || (zero && field.FieldType.Kind() == reflect.Pointer) {
+			token.Values = append(token.Values, nil)
+			continue
+		}
+
+		var text string
+		if t, ok := value.(time.Time); ok {
+			text = t.Format(time.RFC3339Nano)
+		} else {
+			text = fmt.Sprint(value)
+		}
+		token.Values = append(token.Values, &text)
+	}
+
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(cursor string, fingerprint string, columns int) (*cursorToken, error) {
+	invalid := utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Cursor tidak valid")
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	token := new(cursorToken)
+	if err := json.Unmarshal(raw, token); err != nil {
+		return nil, invalid
+	}
+	if token.Sort != fingerprint || len(token.Values) != columns || (token.Direction != cursorNext && token.Direction != cursorPrev) {
+		return nil, invalid
+	}
+	return token, nil
+}
+
+// cursorValues mengubah nilai string di cursor sesuai tipe kolom
+func cursorValues(s *schema.Schema, sort []SortColumn, raw []*string) ([]any, error) {
+	values := make([]any, 0, len(sort))
+	for i, column := range sort {
+		if raw[i] == nil {
+			if !nullable(s, column.Name) {
+				return nil, utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Cursor tidak valid")
+			}
+			values = append(values, nil)
+			continue
+		}
+		value, err := convertValue(s.LookUpField(column.Name), *raw[i])
+		if err != nil {
+			return nil, utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Cursor tidak valid")
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func dereference(value any) any {
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func reverse[T any](items []T) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
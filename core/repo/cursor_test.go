@@ -0,0 +1,163 @@
+package repo
+
+import (
+	"backend/core/utils"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type cursorItem struct {
+	ID   int64 `gorm:"primaryKey;autoIncrement"`
+	Name string
+	Rank *int
+}
+
+func newCursorRepo(t *testing.T) *Repository[cursorItem] {
+	t.Helper()
+
+	db := newTestDB(t, &cursorItem{})
+	rank := func(v int) *int { return &v }
+	// rank dengan nilai kembar dan NULL di beberapa posisi
+	items := []cursorItem{
+		{Name: "a", Rank: rank(2)},
+		{Name: "b"},
+		{Name: "c", Rank: rank(1)},
+		{Name: "d", Rank: rank(2)},
+		{Name: "e"},
+		{Name: "f", Rank: rank(3)},
+		{Name: "g", Rank: rank(1)},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return &Repository[cursorItem]{DB: db}
+}
+
+func cursorNames(items []cursorItem) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+func TestFindWithCursorPaging(t *testing.T) {
+	r := newCursorRepo(t)
+
+	tests := []struct {
+		name string
+		sort []SortColumn
+	}{
+		{"id", nil},
+		{"rank asc", []SortColumn{{Name: "rank"}}},
+		{"rank desc", []SortColumn{{Name: "rank", Desc: true}}},
+		{"rank desc id desc", []SortColumn{{Name: "rank", Desc: true}, {Name: "id", Desc: true}}},
+		{"name desc", []SortColumn{{Name: "name", Desc: true}}},
+	}
+	for _, tt := range tests {
+		// urutan acuan langsung dari ORDER BY database
+		var all []cursorItem
+		query := r.DB.Model(&cursorItem{})
+		for _, column := range withPrimaryKey(mustParse(t, r), tt.sort) {
+			query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column.Name}, Desc: column.Desc})
+		}
+		if err := query.Find(&all).Error; err != nil {
+			t.Fatal(err)
+		}
+		want := cursorNames(all)
+
+		// maju halaman demi halaman sampai next kosong
+		var pages [][]string
+		var cursors []string
+		cursor := ""
+		for {
+			var items []cursorItem
+			next, _, err := r.FindWithCursor(r.DB, &items, cursor, 2, tt.sort)
+			if err != nil {
+				t.Fatalf("%s: forward: %v", tt.name, err)
+			}
+			pages = append(pages, cursorNames(items))
+			cursors = append(cursors, cursor)
+			if next == "" {
+				break
+			}
+			if len(pages) > len(all) {
+				t.Fatalf("%s: paging does not stop", tt.name)
+			}
+			cursor = next
+		}
+
+		var got []string
+		for _, page := range pages {
+			got = append(got, page...)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("%s: forward got %v, want %v", tt.name, got, want)
+		}
+
+		// mundur dari halaman terakhir lewat cursor prev harus menghasilkan halaman yang sama
+		var items []cursorItem
+		_, prev, err := r.FindWithCursor(r.DB, &items, cursors[len(cursors)-1], 2, tt.sort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := len(pages) - 2; i >= 0; i-- {
+			if prev == "" {
+				t.Fatalf("%s: page %d has no prev cursor", tt.name, i+1)
+			}
+			items = nil
+			_, prev, err = r.FindWithCursor(r.DB, &items, prev, 2, tt.sort)
+			if err != nil {
+				t.Fatalf("%s: backward: %v", tt.name, err)
+			}
+			if fmt.Sprint(cursorNames(items)) != fmt.Sprint(pages[i]) {
+				t.Fatalf("%s: backward page %d got %v, want %v", tt.name, i+1, cursorNames(items), pages[i])
+			}
+		}
+		if prev != "" {
+			t.Fatalf("%s: first page reached backward still has prev cursor", tt.name)
+		}
+	}
+}
+
+func TestFindWithCursorInvalid(t *testing.T) {
+	r := newCursorRepo(t)
+
+	var items []cursorItem
+	next, _, err := r.FindWithCursor(r.DB, &items, "", 2, []SortColumn{{Name: "rank"}})
+	if err != nil || next == "" {
+		t.Fatalf("first page: next %q, %v", next, err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   []SortColumn
+	}{
+		{"not base64", "!!!", []SortColumn{{Name: "rank"}}},
+		{"not json", "bm90IGpzb24", []SortColumn{{Name: "rank"}}},
+		// cursor dari urutan lain ditolak
+		{"other sort", next, []SortColumn{{Name: "rank", Desc: true}}},
+	}
+	for _, tt := range tests {
+		_, _, err := r.FindWithCursor(r.DB, &items, tt.cursor, 2, tt.sort)
+		var appErr *utils.AppError
+		if !errors.As(err, &appErr) || appErr.Status != fiber.StatusBadRequest {
+			t.Errorf("%s: got %v, want AppError 400", tt.name, err)
+		}
+	}
+}
+
+func mustParse(t *testing.T, r *Repository[cursorItem]) *schema.Schema {
+	t.Helper()
+	s, err := schema.Parse(new(cursorItem), schemaCache, r.DB.NamingStrategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
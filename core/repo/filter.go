@@ -0,0 +1,306 @@
+package repo
+
+import (
+	"backend/core/utils"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// batas supaya satu query string tidak menghasilkan query yang sangat besar
+const (
+	maxFilters  = 20
+	maxInValues = 100
+)
+
+// filterParam mencocokkan key query string filter[<kolom>][<operator>]
+var filterParam = regexp.MustCompile(`^filter\[([^\]]+)\]\[([a-z]+)\]$`)
+
+var schemaCache = &sync.Map{}
+
+// ParseQuery mengubah query string list endpoint menjadi QueryOption:
+//
+//	filter[kota][eq]=Bandung            kolom = nilai
+//	filter[kota][ne]=Bandung            kolom <> nilai
+//	filter[id][in]=A1,A2                kolom IN (...)
+//	filter[nama_cabang][like]=klinik    kolom LIKE %nilai%
+//	filter[ppn][gt]=10 / lt / gte / lte perbandingan
+//	filter[expire_date][between]=a,b    kolom BETWEEN a AND b
+//	filter[logo][isnull]=true           kolom IS NULL / IS NOT NULL (false)
+//	sort=-regist_date,id                urutan, "-" untuk descending
+//	fields=id,nama_cabang               kolom yang diambil
+//
+// Nama kolom dicek terhadap schema GORM entity T (kolom dengan tag filter:"-" dikecualikan)
+// dan selalu ditulis sebagai identifier ber-quote, tidak pernah disambung ke SQL.
+// Nilai dikonversi sesuai tipe kolom. Error berupa AppError 400
+func (r *Repository[T]) ParseQuery(db *gorm.DB, query map[string]string) ([]QueryOption, error) {
+	columns, err := filterableColumns[T](db)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := make([]QueryOption, 0)
+	details := make([]utils.FieldError, 0)
+	invalid := func(param string, format string, args ...any) {
+		details = append(details, utils.FieldError{Field: param, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// urutkan key supaya SQL dan urutan error selalu sama
+	params := make([]string, 0, len(query))
+	for param := range query {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	filters := 0
+	for _, param := range params {
+		raw := query[param]
+		switch {
+		case param == "sort":
+			sortColumns, err := parseSort(columns, raw)
+			if err != nil {
+				invalid(param, "%v", err)
+				continue
+			}
+			for _, column := range sortColumns {
+				opts = append(opts, withOrderColumn(column.Name, column.Desc))
+			}
+
+		case param == "fields":
+			selected := make([]string, 0)
+			for _, name := range strings.Split(raw, ",") {
+				name = strings.TrimSpace(name)
+				if _, ok := columns[name]; !ok {
+					invalid(param, "kolom %q tidak dikenal", name)
+					continue
+				}
+				selected = append(selected, name)
+			}
+			if len(selected) > 0 {
+				opts = append(opts, withSelectColumns(selected))
+			}
+
+		case strings.HasPrefix(param, "filter["):
+			match := filterParam.FindStringSubmatch(param)
+			if match == nil {
+				invalid(param, "format filter harus filter[kolom][operator]")
+				continue
+			}
+			if filters++; filters > maxFilters {
+				invalid(param, "maksimal %d filter", maxFilters)
+				continue
+			}
+
+			field, ok := columns[match[1]]
+			if !ok {
+				invalid(param, "kolom %q tidak bisa difilter", match[1])
+				continue
+			}
+
+			opt, err := filterOption(field, match[2], raw)
+			if err != nil {
+				invalid(param, "%v", err)
+				continue
+			}
+			opts = append(opts, opt)
+		}
+	}
+
+	if len(details) > 0 {
+		appErr := utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Filter tidak valid")
+		appErr.Details = details
+		return nil, appErr
+	}
+
+	return opts, nil
+}
+
+// ParseSort membaca parameter sort (mis. "-regist_date,id") dengan whitelist yang sama
+// seperti ParseQuery, dipakai untuk FindWithCursor
+func (r *Repository[T]) ParseSort(db *gorm.DB, raw string) ([]SortColumn, error) {
+	columns, err := filterableColumns[T](db)
+	if err != nil {
+		return nil, err
+	}
+
+	sortColumns, err := parseSort(columns, raw)
+	if err != nil {
+		appErr := utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Sort tidak valid")
+		appErr.Details = []utils.FieldError{{Field: "sort", Message: err.Error()}}
+		return nil, appErr
+	}
+	return sortColumns, nil
+}
+
+func parseSort(columns map[string]*schema.Field, raw string) ([]SortColumn, error) {
+	sortColumns := make([]SortColumn, 0)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		desc := strings.HasPrefix(item, "-")
+		name := strings.TrimPrefix(item, "-")
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("kolom %q tidak bisa dipakai untuk sort", name)
+		}
+		sortColumns = append(sortColumns, SortColumn{Name: name, Desc: desc})
+	}
+	return sortColumns, nil
+}
+
+// filterableColumns mengambil whitelist kolom dari schema GORM entity T
+func filterableColumns[T any](db *gorm.DB) (map[string]*schema.Field, error) {
+	s, err := schema.Parse(new(T), schemaCache, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]*schema.Field, len(s.Fields))
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.Tag.Get("filter") == "-" {
+			continue
+		}
+		columns[field.DBName] = field
+	}
+	return columns, nil
+}
+
+func filterOption(field *schema.Field, operator string, raw string) (QueryOption, error) {
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+
+	single := func() (any, error) {
+		return convertValue(field, raw)
+	}
+	where := func(expr clause.Expression) QueryOption {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where(expr)
+		}
+	}
+
+	switch operator {
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+		value, err := single()
+		if err != nil {
+			return nil, err
+		}
+		switch operator {
+		case "eq":
+			return where(clause.Eq{Column: column, Value: value}), nil
+		case "ne":
+			return where(clause.Neq{Column: column, Value: value}), nil
+		case "gt":
+			return where(clause.Gt{Column: column, Value: value}), nil
+		case "gte":
+			return where(clause.Gte{Column: column, Value: value}), nil
+		case "lt":
+			return where(clause.Lt{Column: column, Value: value}), nil
+		default:
+			return where(clause.Lte{Column: column, Value: value}), nil
+		}
+
+	case "in":
+		parts := strings.Split(raw, ",")
+		if len(parts) > maxInValues {
+			return nil, fmt.Errorf("maksimal %d nilai", maxInValues)
+		}
+		values := make([]any, 0, len(parts))
+		for _, part := range parts {
+			value, err := convertValue(field, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return where(clause.IN{Column: column, Values: values}), nil
+
+	case "like":
+		return where(clause.Like{Column: column, Value: "%" + escapeLike(raw) + "%"}), nil
+
+	case "between":
+		parts := strings.Split(raw, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("between butuh dua nilai dipisah koma")
+		}
+		from, err := convertValue(field, strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, err
+		}
+		to, err := convertValue(field, strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		return where(clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{column, from, to}}), nil
+
+	case "isnull":
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("isnull harus true atau false")
+		}
+		if isNull {
+			return where(clause.Expr{SQL: "? IS NULL", Vars: []any{column}}), nil
+		}
+		return where(clause.Expr{SQL: "? IS NOT NULL", Vars: []any{column}}), nil
+	}
+
+	return nil, fmt.Errorf("operator %q tidak dikenal", operator)
+}
+
+// convertValue mengubah nilai string sesuai tipe kolom supaya perbandingan tidak bergantung
+// pada konversi implisit MySQL
+func convertValue(field *schema.Field, raw string) (any, error) {
+	switch field.GORMDataType {
+	case schema.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("nilai %q bukan boolean", raw)
+		}
+		return value, nil
+	case schema.Int, schema.Uint:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("nilai %q bukan bilangan bulat", raw)
+		}
+		return value, nil
+	case schema.Float:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("nilai %q bukan angka", raw)
+		}
+		return value, nil
+	case schema.Time:
+		for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05"} {
+			if value, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("nilai %q bukan tanggal (YYYY-MM-DD)", raw)
+	}
+	return raw, nil
+}
+
+// escapeLike meng-escape wildcard LIKE dari input user supaya dicari apa adanya
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func withOrderColumn(column string, desc bool) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Desc: desc})
+	}
+}
+
+func withSelectColumns(columns []string) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	}
+}
@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"backend/core/utils"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type filterItem struct {
+	ID     int64 `gorm:"primaryKey;autoIncrement"`
+	Code   string
+	City   string
+	Price  float64
+	Qty    int
+	Active bool
+	Logo   *string
+	Secret string `filter:"-"`
+}
+
+func newFilterRepo(t *testing.T) *Repository[filterItem] {
+	t.Helper()
+
+	db := newTestDB(t, &filterItem{})
+	logo := "logo.png"
+	items := []filterItem{
+		{Code: "A", City: "Bandung", Price: 10, Qty: 1, Active: true, Logo: &logo, Secret: "s1"},
+		{Code: "B", City: "Jakarta", Price: 20, Qty: 2, Active: false, Secret: "s2"},
+		{Code: "C", City: "Bandung Barat", Price: 30, Qty: 3, Active: true, Secret: "s3"},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return &Repository[filterItem]{DB: db}
+}
+
+func TestParseQueryOperators(t *testing.T) {
+	r := newFilterRepo(t)
+
+	tests := []struct {
+		name  string
+		query map[string]string
+		want  string
+	}{
+		{"eq", map[string]string{"filter[city][eq]": "Jakarta"}, "[B]"},
+		{"ne", map[string]string{"filter[city][ne]": "Jakarta"}, "[A C]"},
+		{"in", map[string]string{"filter[code][in]": "A, C"}, "[A C]"},
+		{"like", map[string]string{"filter[city][like]": "bandung"}, "[A C]"},
+		{"gt", map[string]string{"filter[price][gt]": "10"}, "[B C]"},
+		{"gte", map[string]string{"filter[price][gte]": "20"}, "[B C]"},
+		{"lt", map[string]string{"filter[qty][lt]": "2"}, "[A]"},
+		{"lte", map[string]string{"filter[qty][lte]": "2"}, "[A B]"},
+		{"between", map[string]string{"filter[price][between]": "15,30"}, "[B C]"},
+		{"isnull true", map[string]string{"filter[logo][isnull]": "true"}, "[B C]"},
+		{"isnull false", map[string]string{"filter[logo][isnull]": "false"}, "[A]"},
+		{"bool", map[string]string{"filter[active][eq]": "true"}, "[A C]"},
+		{"combined", map[string]string{"filter[active][eq]": "true", "filter[price][gt]": "10"}, "[C]"},
+		{"sort desc", map[string]string{"sort": "-price"}, "[C B A]"},
+		{"sort multi", map[string]string{"sort": "-active,code"}, "[A C B]"},
+	}
+	for _, tt := range tests {
+		opts, err := r.ParseQuery(r.DB, tt.query)
+		if err != nil {
+			t.Fatalf("%s: ParseQuery: %v", tt.name, err)
+		}
+		if _, ok := tt.query["sort"]; !ok {
+			opts = append(opts, WithOrder("id"))
+		}
+
+		var items []filterItem
+		if err := r.FindMany(r.DB, &items, opts...); err != nil {
+			t.Fatalf("%s: FindMany: %v", tt.name, err)
+		}
+		codes := make([]string, 0, len(items))
+		for _, item := range items {
+			codes = append(codes, item.Code)
+		}
+		if got := fmt.Sprint(codes); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryFields(t *testing.T) {
+	r := newFilterRepo(t)
+
+	opts, err := r.ParseQuery(r.DB, map[string]string{"fields": "id,code"})
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	var item filterItem
+	if err := r.FindOne(r.DB, &item, append(opts, WithEqual("code", "B"))...); err != nil {
+		t.Fatal(err)
+	}
+	if item.Code != "B" || item.City != "" {
+		t.Fatalf("got %+v, want only id and code", item)
+	}
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	r := newFilterRepo(t)
+
+	tests := []struct {
+		name  string
+		query map[string]string
+		field string
+	}{
+		{"unknown column", map[string]string{"filter[nope][eq]": "x"}, "filter[nope][eq]"},
+		// kolom dengan tag filter:"-" tidak masuk whitelist
+		{"excluded column", map[string]string{"filter[secret][eq]": "s1"}, "filter[secret][eq]"},
+		{"excluded sort", map[string]string{"sort": "secret"}, "sort"},
+		{"excluded fields", map[string]string{"fields": "id,secret"}, "fields"},
+		{"unknown operator", map[string]string{"filter[code][regex]": "A"}, "filter[code][regex]"},
+		{"bad format", map[string]string{"filter[code]": "A"}, "filter[code]"},
+		{"injection in column", map[string]string{"filter[code`;drop][eq]": "A"}, "filter[code`;drop][eq]"},
+		{"not int", map[string]string{"filter[qty][gt]": "abc"}, "filter[qty][gt]"},
+		{"not float", map[string]string{"filter[price][in]": "1,x"}, "filter[price][in]"},
+		{"not bool", map[string]string{"filter[active][eq]": "ya"}, "filter[active][eq]"},
+		{"between one value", map[string]string{"filter[price][between]": "1"}, "filter[price][between]"},
+		{"isnull not bool", map[string]string{"filter[logo][isnull]": "maybe"}, "filter[logo][isnull]"},
+	}
+	for _, tt := range tests {
+		_, err := r.ParseQuery(r.DB, tt.query)
+		var appErr *utils.AppError
+		if !errors.As(err, &appErr) || appErr.Status != fiber.StatusBadRequest {
+			t.Errorf("%s: got %v, want AppError 400", tt.name, err)
+			continue
+		}
+		if len(appErr.Details) != 1 || appErr.Details[0].Field != tt.field {
+			t.Errorf("%s: details %+v, want field %s", tt.name, appErr.Details, tt.field)
+		}
+	}
+}
+
+func TestParseQueryLimits(t *testing.T) {
+	r := newFilterRepo(t)
+
+	query := map[string]string{}
+	for _, column := range []string{"code", "city", "qty", "price"} {
+		for _, operator := range []string{"eq", "ne", "gt", "gte", "lt", "lte"} {
+			query["filter["+column+"]["+operator+"]"] = "1"
+		}
+	}
+	_, err := r.ParseQuery(r.DB, query)
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || len(appErr.Details) != len(query)-maxFilters {
+		t.Fatalf("too many filters: got %v, want %d details", err, len(query)-maxFilters)
+	}
+
+	values := make([]byte, 0)
+	for i := 0; i <= maxInValues; i++ {
+		values = append(values, 'A', ',')
+	}
+	if _, err := r.ParseQuery(r.DB, map[string]string{"filter[code][in]": string(values)}); err == nil {
+		t.Fatal("too many in values: want error")
+	}
+}
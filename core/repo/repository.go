@@ -1,9 +1,10 @@
 package repo
 
 import (
-	"context"
+	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository adalah generic repository untuk entity T
@@ -11,6 +12,20 @@ type Repository[T any] struct {
 	DB *gorm.DB
 }
 
+// VersionColumn adalah kolom optimistic lock yang dipakai UpdateWithVersion
+const VersionColumn = "version"
+
+// SoftDeleteColumn adalah flag soft delete yang dipakai tabel lama (is_delete = 1 berarti terhapus)
+const SoftDeleteColumn = "is_delete"
+
+// ErrEmptyCondition dikembalikan DeleteWhere jika tidak ada kondisi WHERE,
+// supaya opsi yang kosong tidak menghapus seluruh tabel
+var ErrEmptyCondition = errors.New("delete without condition")
+
+// ErrVersionConflict dikembalikan UpdateWithVersion jika record sudah diubah proses lain
+// (version di database tidak sama lagi) atau record tidak ditemukan
+var ErrVersionConflict = errors.New("record version conflict")
+
 // ==========================
 // Query Options
 // ==========================
@@ -29,6 +44,12 @@ func WithOrder(order string) QueryOption {
 	}
 }
 
+func WithSelect(columns ...string) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	}
+}
+
 func WithLimit(limit int) QueryOption {
 	return func(db *gorm.DB) *gorm.DB {
 		return db.Limit(limit)
@@ -47,12 +68,25 @@ func WithPreload(field string) QueryOption {
 	}
 }
 
+// WithSearch, WithEqual dan WithBetween menulis field sebagai identifier ber-quote,
+// jadi field tidak bisa disisipi SQL. Untuk field dari input user tetap pakai ParseQuery
+
 func WithSearch(field string, keyword string) QueryOption {
 	return func(db *gorm.DB) *gorm.DB {
 		if keyword == "" {
 			return db
 		}
-		return db.Where(field+" LIKE ?", "%"+keyword+"%")
+		return db.Where(clause.Like{Column: clause.Column{Name: field}, Value: "%" + keyword + "%"})
+	}
+}
+
+// WithSuffix: field berakhiran suffix, wildcard LIKE di suffix dicari apa adanya
+func WithSuffix(field string, suffix string) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		if suffix == "" {
+			return db
+		}
+		return db.Where(clause.Like{Column: clause.Column{Name: field}, Value: "%" + escapeLike(suffix)})
 	}
 }
 
@@ -61,7 +95,7 @@ func WithEqual(field string, value any) QueryOption {
 		if value == nil {
 			return db
 		}
-		return db.Where(field+" = ?", value)
+		return db.Where(clause.Eq{Column: clause.Column{Name: field}, Value: value})
 	}
 }
 
@@ -70,7 +104,39 @@ func WithBetween(field string, from, to any) QueryOption {
 		if from == nil || to == nil {
 			return db
 		}
-		return db.Where(field+" BETWEEN ? AND ?", from, to)
+		return db.Where(clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{clause.Column{Name: field}, from, to}})
+	}
+}
+
+// ==========================
+// Scopes
+// ==========================
+
+// Kekuatan lock untuk WithLock
+const (
+	ForUpdate = clause.LockingStrengthUpdate
+	ForShare  = clause.LockingStrengthShare
+)
+
+// NotDeleted: hanya record yang belum di-soft delete (is_delete 0 atau NULL)
+func NotDeleted() QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		column := clause.Column{Table: clause.CurrentTable, Name: SoftDeleteColumn}
+		return db.Where(clause.Or(clause.Eq{Column: column, Value: false}, clause.Eq{Column: column, Value: nil}))
+	}
+}
+
+// OnlyDeleted: hanya record yang sudah di-soft delete
+func OnlyDeleted() QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: SoftDeleteColumn}, Value: true})
+	}
+}
+
+// WithLock: SELECT ... FOR UPDATE / FOR SHARE, hanya bermakna di dalam transaksi
+func WithLock(strength string) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(clause.Locking{Strength: strength})
 	}
 }
 
@@ -80,12 +146,12 @@ func WithBetween(field string, from, to any) QueryOption {
 
 // FindAll: ambil semua record tanpa kondisi
 func (r *Repository[T]) FindAll(db *gorm.DB, out *[]T) error {
-	return db.Model(new(T)).Find(out).Error
+	return Conn(db).Model(new(T)).Find(out).Error
 }
 
 // FindMany: ambil banyak record dengan query options
 func (r *Repository[T]) FindMany(db *gorm.DB, out *[]T, opts ...QueryOption) error {
-	query := db.Model(new(T))
+	query := Conn(db).Model(new(T))
 	for _, opt := range opts {
 		query = opt(query)
 	}
@@ -99,7 +165,7 @@ func (r *Repository[T]) FindWithPagination(
 	page, pageSize int,
 	opts ...QueryOption,
 ) (total int64, err error) {
-	query := db.Model(new(T))
+	query := Conn(db).Model(new(T))
 	for _, opt := range opts {
 		query = opt(query)
 	}
@@ -119,9 +185,107 @@ func (r *Repository[T]) FindWithPagination(
 	return
 }
 
+// FindByID: ambil single record berdasarkan primary key, gorm.ErrRecordNotFound jika tidak ada
+func (r *Repository[T]) FindByID(db *gorm.DB, out *T, id any, opts ...QueryOption) error {
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
+	}
+	return query.Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Take(out).Error
+}
+
+// Exists: cek ada record yang cocok tanpa mengambil datanya
+func (r *Repository[T]) Exists(db *gorm.DB, opts ...QueryOption) (bool, error) {
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
+	}
+
+	var found int
+	result := query.Select("1").Limit(1).Scan(&found)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FirstOrCreate: ambil record yang cocok dengan opts, jika tidak ada insert entity.
+// created true jika entity baru dibuat. Kolom unik tetap perlu constraint di database
+// karena dua proses bisa sama-sama tidak menemukan record
+func (r *Repository[T]) FirstOrCreate(db *gorm.DB, entity *T, opts ...QueryOption) (created bool, err error) {
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
+	}
+
+	found := new(T)
+	err = query.Take(found).Error
+	if err == nil {
+		*entity = *found
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	if err = Conn(db).Create(entity).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Pluck: ambil satu kolom ke slice, mis. Pluck(db, "id", &ids)
+func (r *Repository[T]) Pluck(db *gorm.DB, column string, out any, opts ...QueryOption) error {
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
+	}
+	return query.Pluck(column, out).Error
+}
+
+// Chunk: proses record per batch berukuran size (berurutan berdasarkan primary key),
+// berhenti jika fn mengembalikan error
+func (r *Repository[T]) Chunk(db *gorm.DB, size int, fn func(batch []T) error, opts ...QueryOption) error {
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
+	}
+
+	batch := make([]T, 0, size)
+	return query.FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// Iterate: proses record satu per satu dengan cursor database, tanpa memuat semua ke memory.
+// Koneksi terpakai sampai iterasi selesai, jadi jangan query lain di koneksi yang sama di fn
+func (r *Repository[T]) Iterate(db *gorm.DB, fn func(item *T) error, opts ...QueryOption) error {
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := new(T)
+		if err := query.ScanRows(rows, item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // FindOne: ambil single record
 func (r *Repository[T]) FindOne(db *gorm.DB, out *T, opts ...QueryOption) error {
-	query := db.Model(new(T))
+	query := Conn(db).Model(new(T))
 	for _, opt := range opts {
 		query = opt(query)
 	}
@@ -130,73 +294,122 @@ func (r *Repository[T]) FindOne(db *gorm.DB, out *T, opts ...QueryOption) error
 
 // Create: insert 1 record
 func (r *Repository[T]) Create(db *gorm.DB, entity *T) error {
-	return db.Create(entity).Error
+	return Conn(db).Create(entity).Error
 }
 
 // CreateBulk: insert banyak record
 func (r *Repository[T]) CreateBulk(db *gorm.DB, entities []T) error {
-	return db.Create(&entities).Error
+	return Conn(db).Create(&entities).Error
+}
+
+// OnConflict menentukan perilaku Upsert saat insert bentrok dengan unique key.
+// Columns diabaikan MySQL (memakai semua unique key), Update kosong berarti update semua kolom
+type OnConflict struct {
+	Columns   []string
+	Update    []string
+	DoNothing bool
 }
 
-// Update: update full entity (by primary key)
+// Upsert: insert entity, atau update/abaikan jika bentrok sesuai conflict
+func (r *Repository[T]) Upsert(db *gorm.DB, entities []T, conflict OnConflict) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	onConflict := clause.OnConflict{DoNothing: conflict.DoNothing}
+	for _, column := range conflict.Columns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if !conflict.DoNothing {
+		if len(conflict.Update) > 0 {
+			onConflict.DoUpdates = clause.AssignmentColumns(conflict.Update)
+		} else {
+			onConflict.UpdateAll = true
+		}
+	}
+
+	return Conn(db).Clauses(onConflict).Create(&entities).Error
+}
+
+// Update: update full entity (by primary key).
+// Menimpa perubahan proses lain tanpa cek, untuk edit dari user pakai UpdateWithVersion
 func (r *Repository[T]) Update(db *gorm.DB, entity *T) error {
-	return db.Save(entity).Error
+	return Conn(db).Save(entity).Error
 }
 
-// UpdateOne: update sebagian field berdasarkan kondisi
+// UpdateOne: update sebagian field maksimal satu record (LIMIT 1) berdasarkan kondisi.
+// RowsAffected MySQL tidak menghitung baris yang nilainya sama, jadi tidak dipakai untuk cek ada/tidak
 func (r *Repository[T]) UpdateOne(db *gorm.DB, updates map[string]interface{}, opts ...QueryOption) error {
-	query := db.Model(new(T))
+	query := Conn(db).Model(new(T))
 	for _, opt := range opts {
 		query = opt(query)
 	}
-	return query.Updates(updates).Error
+	return query.Limit(1).Updates(updates).Error
 }
 
-// UpdateBulk: update banyak record dengan kondisi
-func (r *Repository[T]) UpdateBulk(db *gorm.DB, updates map[string]interface{}, opts ...QueryOption) error {
-	query := db.Model(new(T))
+// UpdateWithVersion: update sebagian field hanya jika kolom version masih sama dengan version,
+// sekaligus menaikkan version satu angka. ErrVersionConflict jika tidak ada baris yang cocok
+func (r *Repository[T]) UpdateWithVersion(db *gorm.DB, updates map[string]interface{}, version int64, opts ...QueryOption) error {
+	query := Conn(db).Model(new(T))
 	for _, opt := range opts {
 		query = opt(query)
 	}
-	return query.Updates(updates).Error
-}
 
-// Delete: hapus 1 entity
-func (r *Repository[T]) Delete(db *gorm.DB, entity *T) error {
-	return db.Delete(entity).Error
+	values := make(map[string]interface{}, len(updates)+1)
+	for column, value := range updates {
+		values[column] = value
+	}
+	values[VersionColumn] = gorm.Expr(VersionColumn + " + 1")
+
+	result := query.Where(VersionColumn+" = ?", version).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// Count: hitung jumlah record dengan kondisi
-func (r *Repository[T]) Count(db *gorm.DB, opts ...QueryOption) (int64, error) {
-	var total int64
-	query := db.Model(new(T))
+// UpdateBulk: update banyak record dengan kondisi, mengembalikan jumlah baris yang berubah
+func (r *Repository[T]) UpdateBulk(db *gorm.DB, updates map[string]interface{}, opts ...QueryOption) (int64, error) {
+	query := Conn(db).Model(new(T))
 	for _, opt := range opts {
 		query = opt(query)
 	}
-	err := query.Count(&total).Error
-	return total, err
+	result := query.Updates(updates)
+	return result.RowsAffected, result.Error
 }
 
-// ==========================
-// Transaction
-// ==========================
+// Delete: hapus 1 entity
+func (r *Repository[T]) Delete(db *gorm.DB, entity *T) error {
+	return Conn(db).Delete(entity).Error
+}
 
-func ExecuteInTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
-	tx := db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		return tx.Error
+// DeleteWhere: hapus semua record yang cocok dengan opts, mengembalikan jumlah baris terhapus.
+// ErrEmptyCondition jika opts tidak menghasilkan kondisi WHERE
+func (r *Repository[T]) DeleteWhere(db *gorm.DB, opts ...QueryOption) (int64, error) {
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
 	}
 
-	// jalankan function yang dikasih
-	if err := fn(tx); err != nil {
-		tx.Rollback()
-		return err
+	where, ok := query.Statement.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok || len(where.Exprs) == 0 {
+		return 0, ErrEmptyCondition
 	}
 
-	// commit
-	if err := tx.Commit().Error; err != nil {
-		return err
-	}
+	result := query.Delete(new(T))
+	return result.RowsAffected, result.Error
+}
 
-	return nil
+// Count: hitung jumlah record dengan kondisi
+func (r *Repository[T]) Count(db *gorm.DB, opts ...QueryOption) (int64, error) {
+	var total int64
+	query := Conn(db).Model(new(T))
+	for _, opt := range opts {
+		query = opt(query)
+	}
+	err := query.Count(&total).Error
+	return total, err
 }
@@ -0,0 +1,305 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+type testItem struct {
+	ID       int64  `gorm:"primaryKey;autoIncrement"`
+	Code     string `gorm:"uniqueIndex"`
+	Name     string
+	Qty      int
+	IsDelete bool
+}
+
+// newTestDB membuat database SQLite in-memory terpisah untuk setiap test lalu membuat tabel models
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	// satu koneksi supaya database in-memory tidak hilang di antara query
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func newTestRepo(t *testing.T) (*Repository[testItem], *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t, &testItem{})
+	return &Repository[testItem]{DB: db}, db
+}
+
+func seedItems(t *testing.T, db *gorm.DB, items ...testItem) {
+	t.Helper()
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+}
+
+func TestFindByID(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A", Name: "alpha"}, testItem{Code: "B", Name: "beta", IsDelete: true})
+
+	var item testItem
+	if err := r.FindByID(db, &item, 1); err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if item.Code != "A" {
+		t.Fatalf("got code %q, want A", item.Code)
+	}
+
+	if err := r.FindByID(db, &item, 99); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("missing id: got %v, want ErrRecordNotFound", err)
+	}
+
+	// opts tetap berlaku, record yang di-soft delete tidak ditemukan
+	if err := r.FindByID(db, &item, 2, NotDeleted()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("deleted id: got %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A"})
+
+	found, err := r.Exists(db, WithEqual("code", "A"))
+	if err != nil || !found {
+		t.Fatalf("Exists(A) = %v, %v; want true", found, err)
+	}
+
+	found, err = r.Exists(db, WithEqual("code", "Z"))
+	if err != nil || found {
+		t.Fatalf("Exists(Z) = %v, %v; want false", found, err)
+	}
+}
+
+func TestFirstOrCreate(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A", Name: "alpha"})
+
+	existing := testItem{Code: "A", Name: "other"}
+	created, err := r.FirstOrCreate(db, &existing, WithEqual("code", "A"))
+	if err != nil || created {
+		t.Fatalf("existing: created=%v err=%v; want false, nil", created, err)
+	}
+	if existing.Name != "alpha" || existing.ID != 1 {
+		t.Fatalf("existing not loaded: %+v", existing)
+	}
+
+	fresh := testItem{Code: "B", Name: "beta"}
+	created, err = r.FirstOrCreate(db, &fresh, WithEqual("code", "B"))
+	if err != nil || !created {
+		t.Fatalf("fresh: created=%v err=%v; want true, nil", created, err)
+	}
+	if fresh.ID == 0 {
+		t.Fatal("fresh record has no id")
+	}
+}
+
+func TestUpsertDoNothing(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A", Name: "alpha", Qty: 1})
+
+	err := r.Upsert(db, []testItem{
+		{Code: "A", Name: "changed", Qty: 9},
+		{Code: "B", Name: "beta", Qty: 2},
+	}, OnConflict{Columns: []string{"code"}, DoNothing: true})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	var items []testItem
+	if err := r.FindMany(db, &items, WithOrder("code")); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d rows, want 2", len(items))
+	}
+	if items[0].Name != "alpha" || items[0].Qty != 1 {
+		t.Fatalf("conflicting row changed: %+v", items[0])
+	}
+	if items[1].Name != "beta" {
+		t.Fatalf("new row not inserted: %+v", items[1])
+	}
+}
+
+func TestUpsertUpdate(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A", Name: "alpha", Qty: 1})
+
+	// hanya kolom di Update yang ditimpa
+	err := r.Upsert(db, []testItem{{Code: "A", Name: "changed", Qty: 9}},
+		OnConflict{Columns: []string{"code"}, Update: []string{"qty"}})
+	if err != nil {
+		t.Fatalf("Upsert columns: %v", err)
+	}
+
+	var item testItem
+	if err := r.FindOne(db, &item, WithEqual("code", "A")); err != nil {
+		t.Fatal(err)
+	}
+	if item.Qty != 9 || item.Name != "alpha" {
+		t.Fatalf("after column update: %+v; want qty 9, name alpha", item)
+	}
+
+	// Update kosong menimpa semua kolom
+	err = r.Upsert(db, []testItem{{Code: "A", Name: "all", Qty: 3}},
+		OnConflict{Columns: []string{"code"}})
+	if err != nil {
+		t.Fatalf("Upsert all: %v", err)
+	}
+	if err := r.FindOne(db, &item, WithEqual("code", "A")); err != nil {
+		t.Fatal(err)
+	}
+	if item.Qty != 3 || item.Name != "all" {
+		t.Fatalf("after update all: %+v; want qty 3, name all", item)
+	}
+
+	total, err := r.Count(db)
+	if err != nil || total != 1 {
+		t.Fatalf("Count = %d, %v; want 1", total, err)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	r, db := newTestRepo(t)
+	for i := 1; i <= 5; i++ {
+		seedItems(t, db, testItem{Code: fmt.Sprintf("C%d", i), Qty: i})
+	}
+
+	var sizes []int
+	seen := 0
+	err := r.Chunk(db, 2, func(batch []testItem) error {
+		sizes = append(sizes, len(batch))
+		seen += len(batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Chunk: %v", err)
+	}
+	if seen != 5 || fmt.Sprint(sizes) != "[2 2 1]" {
+		t.Fatalf("batches %v (total %d); want [2 2 1]", sizes, seen)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = r.Chunk(db, 2, func(batch []testItem) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("Chunk stop: err=%v calls=%d; want stop after 1 call", err, calls)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A", Qty: 1}, testItem{Code: "B", Qty: 2}, testItem{Code: "C", Qty: 3})
+
+	var codes []string
+	err := r.Iterate(db, func(item *testItem) error {
+		codes = append(codes, item.Code)
+		return nil
+	}, WithWhere("qty >= ?", 2), WithOrder("id"))
+	if err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	if fmt.Sprint(codes) != "[B C]" {
+		t.Fatalf("got %v, want [B C]", codes)
+	}
+}
+
+func TestPluck(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A"}, testItem{Code: "B"}, testItem{Code: "C", IsDelete: true})
+
+	var codes []string
+	if err := r.Pluck(db, "code", &codes, NotDeleted(), WithOrder("code")); err != nil {
+		t.Fatalf("Pluck: %v", err)
+	}
+	if fmt.Sprint(codes) != "[A B]" {
+		t.Fatalf("got %v, want [A B]", codes)
+	}
+}
+
+func TestDeleteWhere(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A", Qty: 1}, testItem{Code: "B", Qty: 1}, testItem{Code: "C", Qty: 2})
+
+	if _, err := r.DeleteWhere(db); !errors.Is(err, ErrEmptyCondition) {
+		t.Fatalf("no opts: got %v, want ErrEmptyCondition", err)
+	}
+	// option yang tidak menambah WHERE (nilai nil) juga ditolak
+	if _, err := r.DeleteWhere(db, WithEqual("code", nil), WithOrder("id")); !errors.Is(err, ErrEmptyCondition) {
+		t.Fatalf("empty opts: got %v, want ErrEmptyCondition", err)
+	}
+
+	deleted, err := r.DeleteWhere(db, WithEqual("qty", 1))
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteWhere = %d, %v; want 2", deleted, err)
+	}
+
+	total, err := r.Count(db)
+	if err != nil || total != 1 {
+		t.Fatalf("Count = %d, %v; want 1", total, err)
+	}
+}
+
+func TestNotDeletedAndOnlyDeleted(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A"}, testItem{Code: "B", IsDelete: true})
+	// is_delete NULL dari data lama dianggap belum terhapus
+	if err := db.Exec("INSERT INTO test_items (code, is_delete) VALUES ('C', NULL)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := r.Count(db, NotDeleted())
+	if err != nil || active != 2 {
+		t.Fatalf("NotDeleted count = %d, %v; want 2", active, err)
+	}
+
+	deleted, err := r.Count(db, OnlyDeleted())
+	if err != nil || deleted != 1 {
+		t.Fatalf("OnlyDeleted count = %d, %v; want 1", deleted, err)
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	r, db := newTestRepo(t)
+	seedItems(t, db, testItem{Code: "A"})
+
+	// SQLite tidak menulis FOR UPDATE ke SQL, jadi cek clause terpasang dan query tetap jalan di transaksi
+	for _, strength := range []string{ForUpdate, ForShare} {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var item testItem
+			query := WithLock(strength)(tx.Model(&testItem{}))
+			locking, ok := query.Statement.Clauses["FOR"].Expression.(clause.Locking)
+			if !ok || locking.Strength != strength {
+				t.Fatalf("%s: locking clause %+v not set", strength, query.Statement.Clauses["FOR"])
+			}
+			return r.FindByID(tx, &item, 1, WithLock(strength))
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", strength, err)
+		}
+	}
+}
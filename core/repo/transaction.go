@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"backend/core/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm"
+)
+
+// kode error MySQL yang aman diulang dari awal transaksi
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+)
+
+// TxOptions mengatur transaksi UnitOfWork. MaxRetries adalah jumlah ulang maksimal
+// jika transaksi gagal karena deadlock atau lock wait timeout, RetryDelay dikali nomor percobaan
+type TxOptions struct {
+	Isolation  sql.IsolationLevel
+	ReadOnly   bool
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// UnitOfWork menjalankan fungsi di dalam satu transaksi yang disimpan di context.
+// Repository yang menerima db dengan context tersebut (s.DB.WithContext(ctx)) otomatis
+// memakai transaksi yang sama, jadi service bisa memanggil service lain tanpa meneruskan tx.
+// Do di dalam Do membuat savepoint, bukan transaksi baru
+type UnitOfWork struct {
+	DB      *gorm.DB
+	Options TxOptions
+}
+
+func NewUnitOfWork(db *gorm.DB, options TxOptions) *UnitOfWork {
+	return &UnitOfWork{
+		DB:      db,
+		Options: options,
+	}
+}
+
+type txContextKey struct{}
+
+// txState adalah transaksi aktif di context, depth untuk penamaan savepoint
+type txState struct {
+	tx    *gorm.DB
+	depth int
+}
+
+// TxFromContext mengembalikan transaksi aktif di context jika ada
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	state, ok := ctx.Value(txContextKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return state.tx, true
+}
+
+// Conn mengembalikan db yang memakai transaksi dari context db (jika ada). Dipanggil
+// semua method Repository; method repository custom yang menulis query sendiri
+// sebaiknya juga memanggil Conn
+func Conn(db *gorm.DB) *gorm.DB {
+	tx, ok := TxFromContext(db.Statement.Context)
+	if !ok {
+		return db
+	}
+	// db sudah di dalam transaksi (tx diteruskan langsung)
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return db
+	}
+
+	conn := db.Session(&gorm.Session{Context: db.Statement.Context})
+	conn.Statement.ConnPool = tx.Statement.ConnPool
+	return conn
+}
+
+// Do menjalankan fn di dalam transaksi. fn bisa dijalankan ulang saat deadlock,
+// jadi efek di luar database (kirim email, upload, dst) jangan dilakukan di dalam fn.
+// Panic di fn me-rollback transaksi lalu diteruskan
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	return runInTransaction(ctx, u.DB, u.Options, fn)
+}
+
+// ExecuteInTransaction menjalankan fn di dalam transaksi tanpa retry. Jika context sudah
+// berisi transaksi (dari UnitOfWork), fn berjalan di savepoint transaksi tersebut
+func ExecuteInTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return runInTransaction(ctx, db, TxOptions{}, func(_ context.Context, tx *gorm.DB) error {
+		return fn(tx)
+	})
+}
+
+func runInTransaction(ctx context.Context, db *gorm.DB, options TxOptions, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	if state, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		return runInSavepoint(ctx, state, fn)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := runOnce(ctx, db, options, attempt, fn)
+		if err == nil || attempt >= options.MaxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(options.RetryDelay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func runOnce(ctx context.Context, db *gorm.DB, options TxOptions, attempt int, fn func(ctx context.Context, tx *gorm.DB) error) (err error) {
+	// span transaksi, query di dalamnya menjadi child span
+	ctx, span := tracing.Tracer().Start(ctx, "db.transaction")
+	span.SetAttributes(attribute.Int("db.transaction.attempt", attempt+1))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	tx := db.WithContext(ctx).Begin(&sql.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	state := &txState{tx: tx}
+	ctx = context.WithValue(ctx, txContextKey{}, state)
+	state.tx = tx.WithContext(ctx)
+
+	// rollback jika fn panic supaya koneksi tidak tertahan dengan transaksi terbuka
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	if err := fn(ctx, state.tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func runInSavepoint(ctx context.Context, parent *txState, fn func(ctx context.Context, tx *gorm.DB) error) (err error) {
+	state := &txState{tx: parent.tx, depth: parent.depth + 1}
+	name := fmt.Sprintf("sp_%d", state.depth)
+
+	if err := parent.tx.SavePoint(name).Error; err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, txContextKey{}, state)
+	state.tx = parent.tx.WithContext(ctx)
+
+	done := false
+	defer func() {
+		// deadlock sudah me-rollback seluruh transaksi di MySQL, error rollback savepoint diabaikan
+		if !done {
+			parent.tx.RollbackTo(name)
+		}
+	}()
+
+	if err := fn(ctx, state.tx); err != nil {
+		return err
+	}
+	done = true
+	return nil
+}
+
+// IsRetryable true untuk error MySQL deadlock dan lock wait timeout
+func IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+}
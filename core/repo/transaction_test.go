@@ -0,0 +1,208 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// itemCodes mengembalikan code semua item, dibatasi timeout supaya test gagal
+// (bukan menggantung) jika transaksi masih memegang satu-satunya koneksi
+func itemCodes(t *testing.T, r *Repository[testItem], db *gorm.DB) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var items []string
+	if err := r.Pluck(db.WithContext(ctx), "code", &items, WithOrder("code")); err != nil {
+		t.Fatalf("read items: %v", err)
+	}
+	return fmt.Sprint(items)
+}
+
+func TestUnitOfWorkCommit(t *testing.T) {
+	r, db := newTestRepo(t)
+	uow := NewUnitOfWork(db, TxOptions{})
+
+	err := uow.Do(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+		if _, ok := TxFromContext(ctx); !ok {
+			t.Fatal("context has no transaction")
+		}
+		// repository dengan db dari context ikut transaksi yang sama
+		return r.Create(db.WithContext(ctx), &testItem{Code: "A"})
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got := itemCodes(t, r, db); got != "[A]" {
+		t.Fatalf("got %s, want [A]", got)
+	}
+}
+
+func TestUnitOfWorkNestedRollback(t *testing.T) {
+	r, db := newTestRepo(t)
+	uow := NewUnitOfWork(db, TxOptions{})
+	failed := errors.New("inner failed")
+
+	err := uow.Do(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+		if err := r.Create(db.WithContext(ctx), &testItem{Code: "A"}); err != nil {
+			return err
+		}
+
+		// Do di dalam Do hanya me-rollback savepoint-nya sendiri
+		err := uow.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			if err := r.Create(db.WithContext(ctx), &testItem{Code: "B"}); err != nil {
+				return err
+			}
+			return failed
+		})
+		if !errors.Is(err, failed) {
+			t.Fatalf("inner Do: %v, want inner failed", err)
+		}
+
+		// savepoint berikutnya tetap bisa dipakai
+		return ExecuteInTransaction(ctx, db, func(tx *gorm.DB) error {
+			return r.Create(tx, &testItem{Code: "C"})
+		})
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got := itemCodes(t, r, db); got != "[A C]" {
+		t.Fatalf("got %s, want [A C]", got)
+	}
+}
+
+func TestUnitOfWorkOuterRollback(t *testing.T) {
+	r, db := newTestRepo(t)
+	uow := NewUnitOfWork(db, TxOptions{})
+	failed := errors.New("outer failed")
+
+	err := uow.Do(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+		if err := r.Create(db.WithContext(ctx), &testItem{Code: "A"}); err != nil {
+			return err
+		}
+		// savepoint yang berhasil tetap ikut di-rollback bersama transaksi luar
+		if err := uow.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			return r.Create(db.WithContext(ctx), &testItem{Code: "B"})
+		}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Do: %v, want outer failed", err)
+	}
+	if got := itemCodes(t, r, db); got != "[]" {
+		t.Fatalf("got %s, want []", got)
+	}
+}
+
+func TestUnitOfWorkRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+		wantCodes string
+	}{
+		{"deadlock then success", []error{&mysql.MySQLError{Number: mysqlErrDeadlock}}, 2, false, "[A]"},
+		{"lock wait timeout then success", []error{&mysql.MySQLError{Number: mysqlErrLockWaitTimeout}}, 2, false, "[A]"},
+		{"retries exhausted", []error{
+			&mysql.MySQLError{Number: mysqlErrDeadlock},
+			&mysql.MySQLError{Number: mysqlErrDeadlock},
+			&mysql.MySQLError{Number: mysqlErrDeadlock},
+		}, 3, true, "[]"},
+		// error lain tidak diulang
+		{"duplicate entry", []error{&mysql.MySQLError{Number: 1062}}, 1, true, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, db := newTestRepo(t)
+			uow := NewUnitOfWork(db, TxOptions{MaxRetries: 2, RetryDelay: time.Millisecond})
+
+			calls := 0
+			err := uow.Do(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+				calls++
+				// insert di percobaan yang gagal harus ikut di-rollback
+				if err := r.Create(db.WithContext(ctx), &testItem{Code: "A"}); err != nil {
+					return err
+				}
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Do: %v, want error %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+			if got := itemCodes(t, r, db); got != tt.wantCodes {
+				t.Fatalf("got %s, want %s", got, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestExecuteInTransactionNoRetry(t *testing.T) {
+	_, db := newTestRepo(t)
+
+	calls := 0
+	err := ExecuteInTransaction(context.Background(), db, func(tx *gorm.DB) error {
+		calls++
+		return &mysql.MySQLError{Number: mysqlErrDeadlock}
+	})
+	if !IsRetryable(err) || calls != 1 {
+		t.Fatalf("err %v calls %d, want deadlock after 1 call", err, calls)
+	}
+}
+
+func TestUnitOfWorkPanicRollback(t *testing.T) {
+	r, db := newTestRepo(t)
+	uow := NewUnitOfWork(db, TxOptions{})
+
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != "boom" {
+				t.Fatalf("recovered %v, want boom", recovered)
+			}
+		}()
+		uow.Do(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+			if err := r.Create(db.WithContext(ctx), &testItem{Code: "A"}); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+
+	// koneksi dilepas dan insert di-rollback
+	if got := itemCodes(t, r, db); got != "[]" {
+		t.Fatalf("got %s, want []", got)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&mysql.MySQLError{Number: mysqlErrDeadlock}, true},
+		{fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: mysqlErrLockWaitTimeout}), true},
+		{&mysql.MySQLError{Number: 1062}, false},
+		{errors.New("deadlock"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package routes
+
+import (
+	"backend/core/openapi"
+	"backend/core/utils"
+	"backend/web/model"
+)
+
+const (
+	OpenAPIPath = "/openapi.json"
+	DocsPath    = "/docs"
+)
+
+var idempotencyKeyHeader = openapi.Parameter{
+	Name:        "Idempotency-Key",
+	Description: "Key unik per aksi; retry dengan key dan body yang sama mendapat response yang sama",
+}
+
+// pageQuery adalah parameter pagination list endpoint
+var pageQuery = []openapi.Parameter{
+	{Name: "page", Description: "Halaman, mulai dari 1", Schema: &openapi.Schema{Type: "integer"}},
+	{Name: "size", Description: "Jumlah data per halaman, maksimal 100", Schema: &openapi.Schema{Type: "integer"}},
+}
+
+// listQuery adalah parameter query string list endpoint yang dibaca repo.ParseQuery
+var listQuery = append(append([]openapi.Parameter{}, pageQuery...), []openapi.Parameter{
+	{Name: "cursor", Description: "Pagination cursor sebagai ganti page: kirim kosong untuk halaman pertama, lalu nilai cursor.next/cursor.prev dari response"},
+	{Name: "sort", Description: "Kolom dipisah koma, awali dengan - untuk descending, mis. -regist_date,id"},
+	{Name: "fields", Description: "Kolom yang diambil dipisah koma, mis. id,nama_cabang"},
+	{Name: "filter[kolom][operator]", Description: "Operator: eq, ne, in, like, gt, gte, lt, lte, between, isnull. Mis. filter[kota][eq]=Bandung"},
+}...)
+
+// statsHistoryQuery adalah parameter periode riwayat statistik
+var statsHistoryQuery = []openapi.Parameter{
+	{Name: "range", Description: "YYYY-MM-DD atau YYYY-MM-DD|YYYY-MM-DD, maksimal 366 hari. Default 30 hari terakhir"},
+	{Name: "interval", Description: "daily (default), weekly atau monthly. Nilai per periode adalah rata-rata snapshot"},
+}
+
+// Operations adalah dokumentasi setiap route di Setup. Route yang terdaftar
+// tanpa entry di sini membuat `go test ./core/routes` dan `make openapi-check` gagal
+func (c *RouteConfig) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method:  "GET",
+			Path:    "/metrics",
+			Summary: "Metrics prometheus",
+			Tags:    []string{"monitoring"},
+		},
+		{
+			Method:   "POST",
+			Path:     "/branch/management",
+			Summary:  "Tambah manajemen baru",
+			Tags:     []string{"branch"},
+			Headers:  []openapi.Parameter{idempotencyKeyHeader},
+			Request:  model.CreateManagementRequest{},
+			Response: utils.WebResponse[*model.CreateManagementResponse]{},
+			Responses: map[int]string{
+				409: "ID sudah dipakai atau Idempotency-Key dipakai untuk body berbeda",
+			},
+		},
+		{
+			Method:   "POST",
+			Path:     "/branch",
+			Summary:  "Tambah cabang baru",
+			Tags:     []string{"branch"},
+			Headers:  []openapi.Parameter{idempotencyKeyHeader},
+			Request:  model.CreateBranchRequest{},
+			Response: utils.WebResponse[*model.CreateBranchResponse]{},
+			Responses: map[int]string{
+				409: "ID sudah dipakai atau Idempotency-Key dipakai untuk body berbeda",
+			},
+		},
+		{
+			Method:   "GET",
+			Path:     "/branch",
+			Summary:  "Daftar cabang",
+			Tags:     []string{"branch"},
+			Auth:     true,
+			Query:    listQuery,
+			Response: utils.WebResponse[[]*model.BranchResponse]{},
+		},
+		{
+			Method:      "GET",
+			Path:        "/branch/:id",
+			Summary:     "Detail cabang",
+			Description: "Header ETag berisi version cabang, kirim kembali di If-Match saat PATCH",
+			Tags:        []string{"branch"},
+			Auth:        true,
+			Headers: []openapi.Parameter{
+				{Name: "If-None-Match", Description: "ETag terakhir; 304 jika data belum berubah"},
+			},
+			Response: utils.WebResponse[*model.BranchResponse]{},
+			Responses: map[int]string{
+				304: "Data belum berubah",
+			},
+		},
+		{
+			Method:      "PATCH",
+			Path:        "/branch/:id",
+			Summary:     "Ubah sebagian data cabang",
+			Description: "Hanya field yang dikirim yang diubah, upline memindah cabang ke manajemen lain. Response membawa ETag baru",
+			Tags:        []string{"branch"},
+			Auth:        true,
+			Headers: []openapi.Parameter{
+				{Name: "If-Match", Description: "ETag dari GET /branch/:id", Required: true},
+				idempotencyKeyHeader,
+			},
+			Request:  model.UpdateBranchRequest{},
+			Response: utils.WebResponse[*model.BranchResponse]{},
+			Responses: map[int]string{
+				412: "Cabang sudah diubah pengguna lain sejak ETag diambil",
+				428: "Header If-Match tidak dikirim",
+			},
+		},
+		{
+			Method:      "POST",
+			Path:        "/branch/:id/logo",
+			Summary:     "Upload logo cabang",
+			Description: "Logo lama dihapus setelah logo baru tersimpan. Response membawa ETag baru",
+			Tags:        []string{"branch"},
+			Auth:        true,
+			Headers:     []openapi.Parameter{idempotencyKeyHeader},
+			ContentType: "multipart/form-data",
+			Request:     model.UpdateLogoRequest{},
+			Response:    utils.WebResponse[*model.BranchResponse]{},
+		},
+		{
+			Method:      "PUT",
+			Path:        "/branch/:id/stats",
+			Summary:     "Push statistik dashboard cabang",
+			Description: "Semua statistik diganti. Push dengan measuredAt yang tidak lebih baru dari push terakhir ditolak. Response membawa ETag baru",
+			Tags:        []string{"branch"},
+			Auth:        true,
+			Headers:     []openapi.Parameter{idempotencyKeyHeader},
+			Request:     model.BranchStatsRequest{},
+			Response:    utils.WebResponse[*model.BranchResponse]{},
+			Responses: map[int]string{
+				409: "measuredAt tidak lebih baru dari statistik yang sudah tersimpan",
+			},
+		},
+		{
+			Method:      "PUT",
+			Path:        "/branch/stats",
+			Summary:     "Push statistik dashboard banyak cabang",
+			Description: "Maksimal 100 cabang, setiap cabang disimpan terpisah. Status per cabang: updated, stale, not_found atau failed",
+			Tags:        []string{"branch"},
+			Auth:        true,
+			Headers:     []openapi.Parameter{idempotencyKeyHeader},
+			Request:     model.BranchStatsBatchRequest{},
+			Response:    utils.WebResponse[[]*model.BranchStatsBatchResult]{},
+		},
+		{
+			Method:   "GET",
+			Path:     "/branch/:id/stats/history",
+			Summary:  "Riwayat statistik dashboard cabang",
+			Tags:     []string{"branch"},
+			Auth:     true,
+			Query:    statsHistoryQuery,
+			Response: utils.WebResponse[*model.BranchStatsHistoryResponse]{},
+		},
+		{
+			Method:  "GET",
+			Path:    "/branch/stats/compare",
+			Summary: "Bandingkan riwayat statistik beberapa cabang",
+			Tags:    []string{"branch"},
+			Auth:    true,
+			Query: append([]openapi.Parameter{
+				{Name: "ids", Description: "ID cabang dipisah koma, maksimal 20", Required: true},
+			}, statsHistoryQuery...),
+			Response: utils.WebResponse[[]*model.BranchStatsHistoryResponse]{},
+		},
+		{
+			Method:      "POST",
+			Path:        "/webhook",
+			Summary:     "Tambah webhook",
+			Description: "Secret kosong dibuatkan otomatis dan hanya ditampilkan di response ini. Events berisi tipe event atau * untuk semua",
+			Tags:        []string{"webhook"},
+			Auth:        true,
+			Headers:     []openapi.Parameter{idempotencyKeyHeader},
+			Request:     model.CreateWebhookRequest{},
+			Response:    utils.WebResponse[*model.WebhookResponse]{},
+		},
+		{
+			Method:   "GET",
+			Path:     "/webhook",
+			Summary:  "Daftar webhook",
+			Tags:     []string{"webhook"},
+			Auth:     true,
+			Query:    pageQuery,
+			Response: utils.WebResponse[[]*model.WebhookResponse]{},
+		},
+		{
+			Method:   "GET",
+			Path:     "/webhook/:id",
+			Summary:  "Detail webhook",
+			Tags:     []string{"webhook"},
+			Auth:     true,
+			Response: utils.WebResponse[*model.WebhookResponse]{},
+		},
+		{
+			Method:   "PATCH",
+			Path:     "/webhook/:id",
+			Summary:  "Ubah webhook",
+			Tags:     []string{"webhook"},
+			Auth:     true,
+			Headers:  []openapi.Parameter{idempotencyKeyHeader},
+			Request:  model.UpdateWebhookRequest{},
+			Response: utils.WebResponse[*model.WebhookResponse]{},
+		},
+		{
+			Method:   "DELETE",
+			Path:     "/webhook/:id",
+			Summary:  "Hapus webhook beserta riwayat delivery",
+			Tags:     []string{"webhook"},
+			Auth:     true,
+			Headers:  []openapi.Parameter{idempotencyKeyHeader},
+			Response: utils.WebResponse[bool]{},
+		},
+		{
+			Method:  "GET",
+			Path:    "/webhook/:id/deliveries",
+			Summary: "Riwayat delivery webhook",
+			Tags:    []string{"webhook"},
+			Auth:    true,
+			Query: append([]openapi.Parameter{
+				{Name: "status", Description: "pending, delivered atau dead"},
+			}, pageQuery...),
+			Response: utils.WebResponse[[]*model.WebhookDeliveryResponse]{},
+		},
+		{
+			Method:      "POST",
+			Path:        "/webhook/:id/deliveries/:deliveryId/redeliver",
+			Summary:     "Kirim ulang delivery webhook",
+			Description: "Delivery dijadwalkan ulang dengan jatah percobaan baru, termasuk yang sudah dead",
+			Tags:        []string{"webhook"},
+			Auth:        true,
+			Headers:     []openapi.Parameter{idempotencyKeyHeader},
+			Response:    utils.WebResponse[*model.WebhookDeliveryResponse]{},
+		},
+	}
+}
+
+// OpenAPI membangun dokumen OpenAPI dari Operations
+func (c *RouteConfig) OpenAPI() *openapi.Document {
+	return openapi.Build(
+		openapi.Info{Title: c.AppName, Version: "1.0.0"},
+		utils.ErrorResponse{},
+		c.Operations(),
+	)
+}
+
+// SetupDocsRoute menyajikan /openapi.json, dan Swagger UI di /docs saat mode dev
+func (c *RouteConfig) SetupDocsRoute() {
+	c.App.Get(OpenAPIPath, openapi.Handler(c.OpenAPI()))
+	if c.DevMode {
+		c.App.Get(DocsPath, openapi.SwaggerUI(c.AppName, OpenAPIPath))
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"backend/core/openapi"
+	"testing"
+)
+
+func TestEveryRouteHasOpenAPISpec(t *testing.T) {
+	routeConfig := newTestRouteConfig()
+	routeConfig.Setup()
+
+	missing := openapi.Missing(routeConfig.App.GetRoutes(true), routeConfig.Operations(), OpenAPIPath, DocsPath)
+	for _, route := range missing {
+		t.Errorf("route tanpa spec OpenAPI: %s", route)
+	}
+}
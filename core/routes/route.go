@@ -7,21 +7,67 @@ import (
 )
 
 type RouteConfig struct {
-	App               *fiber.App
-	LogMiddleware     fiber.Handler
-	BranchsController *controller.BranchsController
+	AppName               string
+	DevMode               bool
+	App                   *fiber.App
+	RequestIDMiddleware   fiber.Handler
+	TracingMiddleware     fiber.Handler
+	LogMiddleware         fiber.Handler
+	IdempotencyMiddleware fiber.Handler
+	GuestRateLimit        fiber.Handler
+	AuthRateLimit         fiber.Handler
+	AuthMiddleware        fiber.Handler
+	MetricsHandler        fiber.Handler
+	BranchsController     *controller.BranchsController
+	WebhookController     *controller.WebhookController
 }
 
 func (c *RouteConfig) Setup() {
+	c.App.Use(c.RequestIDMiddleware)
+	c.App.Use(c.TracingMiddleware)
 	c.App.Use(c.LogMiddleware)
-	c.SetupGuestRoute()
+	c.App.Get("/metrics", c.MetricsHandler)
+	// route auth lebih dulu: fiber menjalankan handler sesuai urutan daftar, jadi route
+	// /branch yang butuh auth selesai sebelum middleware group branch guest sempat jalan
 	c.SetupAuthRoute()
+	c.SetupGuestRoute()
+	c.SetupDocsRoute()
 }
 
 func (c *RouteConfig) SetupGuestRoute() {
-	branch := c.App.Group("branch")
+	// endpoint OTP dan login didaftarkan di group ini supaya kena batas yang lebih ketat
+	c.App.Group("auth", c.AuthRateLimit)
+
+	branch := c.App.Group("branch", c.GuestRateLimit, c.IdempotencyMiddleware)
 	branch.Post("/management", c.BranchsController.AddNewManagement)
 	branch.Post("/", c.BranchsController.AddNewBranch)
 }
 
-func (c *RouteConfig) SetupAuthRoute() {}
+func (c *RouteConfig) SetupAuthRoute() {
+	// rate limit setelah auth supaya batas per user ikut berlaku
+	webhook := c.App.Group("webhook", c.AuthMiddleware, c.GuestRateLimit, c.IdempotencyMiddleware)
+	webhook.Post("/", c.WebhookController.Create)
+	webhook.Get("/", c.WebhookController.List)
+	webhook.Get("/:id", c.WebhookController.Get)
+	webhook.Patch("/:id", c.WebhookController.Update)
+	webhook.Delete("/:id", c.WebhookController.Delete)
+	webhook.Get("/:id/deliveries", c.WebhookController.ListDeliveries)
+	webhook.Post("/:id/deliveries/:deliveryId/redeliver", c.WebhookController.Redeliver)
+
+	// group tanpa handler supaya auth tidak ikut terpasang di route branch guest,
+	// urutan middleware sama dengan group webhook dipasang per route
+	branch := c.App.Group("branch")
+	branch.Put("/stats", c.withAuth(c.BranchsController.UpdateStatsBatch)...)
+	branch.Put("/:id/stats", c.withAuth(c.BranchsController.UpdateStats)...)
+	branch.Get("/stats/compare", c.withAuth(c.BranchsController.CompareStats)...)
+	branch.Get("/:id/stats/history", c.withAuth(c.BranchsController.StatsHistory)...)
+	branch.Get("/", c.withAuth(c.BranchsController.ListBranches)...)
+	branch.Get("/:id", c.withAuth(c.BranchsController.GetBranch)...)
+	branch.Patch("/:id", c.withAuth(c.BranchsController.UpdateBranch)...)
+	branch.Post("/:id/logo", c.withAuth(c.BranchsController.UpdateLogo)...)
+}
+
+// withAuth memasang auth, rate limit lalu Idempotency-Key sebelum handler
+func (c *RouteConfig) withAuth(handler fiber.Handler) []fiber.Handler {
+	return []fiber.Handler{c.AuthMiddleware, c.GuestRateLimit, c.IdempotencyMiddleware, handler}
+}
@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"backend/web/controller"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newTestRouteConfig mengisi semua middleware dengan no-op dan controller tanpa dependency,
+// cukup untuk mendaftarkan route tanpa database. Setup dipanggil oleh test
+func newTestRouteConfig() *RouteConfig {
+	noop := func(c *fiber.Ctx) error { return c.Next() }
+
+	return &RouteConfig{
+		AppName:               "api-aestech-panel",
+		DevMode:               true,
+		App:                   fiber.New(),
+		RequestIDMiddleware:   noop,
+		TracingMiddleware:     noop,
+		LogMiddleware:         noop,
+		IdempotencyMiddleware: noop,
+		GuestRateLimit:        noop,
+		AuthRateLimit:         noop,
+		AuthMiddleware:        noop,
+		MetricsHandler:        noop,
+		BranchsController:     &controller.BranchsController{},
+		WebhookController:     &controller.WebhookController{},
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	routeConfig := newTestRouteConfig()
+
+	var calls []string
+	record := func(name string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			calls = append(calls, name)
+			return c.Next()
+		}
+	}
+	routeConfig.AuthMiddleware = record("auth")
+	routeConfig.GuestRateLimit = record("ratelimit")
+	// berhenti di Idempotency-Key supaya controller tanpa dependency tidak dipanggil
+	routeConfig.IdempotencyMiddleware = func(c *fiber.Ctx) error {
+		calls = append(calls, "idempotency")
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+	routeConfig.Setup()
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		// rate limit setelah auth supaya bucket per user berlaku
+		{fiber.MethodPut, "/branch/stats", "auth,ratelimit,idempotency"},
+		{fiber.MethodPut, "/branch/1/stats", "auth,ratelimit,idempotency"},
+		{fiber.MethodGet, "/branch/stats/compare", "auth,ratelimit,idempotency"},
+		{fiber.MethodGet, "/branch/1/stats/history", "auth,ratelimit,idempotency"},
+		{fiber.MethodGet, "/branch", "auth,ratelimit,idempotency"},
+		{fiber.MethodGet, "/branch/1", "auth,ratelimit,idempotency"},
+		{fiber.MethodPatch, "/branch/1", "auth,ratelimit,idempotency"},
+		{fiber.MethodPost, "/branch/1/logo", "auth,ratelimit,idempotency"},
+		{fiber.MethodPost, "/webhook", "auth,ratelimit,idempotency"},
+		// route guest tidak butuh auth
+		{fiber.MethodPost, "/branch/management", "ratelimit,idempotency"},
+	}
+
+	for _, tt := range tests {
+		calls = nil
+		resp, err := routeConfig.App.Test(httptest.NewRequest(tt.method, tt.path, nil))
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+		}
+		if resp.StatusCode != fiber.StatusNoContent {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, resp.StatusCode, fiber.StatusNoContent)
+		}
+		if got := strings.Join(calls, ","); got != tt.want {
+			t.Errorf("%s %s: middleware %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalPath(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocal(dir, "/uploads/")
+
+	got, err := store.path("2026/10/19/logo.png")
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	if want := filepath.Join(dir, "2026", "10", "19", "logo.png"); got != want {
+		t.Fatalf("path = %q, want %q", got, want)
+	}
+
+	for _, key := range []string{"", "../escape.png", "a/../../escape.png", "/etc/passwd"} {
+		if _, err := store.path(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("path(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+
+	if url := store.URL("2026/logo.png"); url != "/uploads/2026/logo.png" {
+		t.Fatalf("URL = %q, want /uploads/2026/logo.png", url)
+	}
+}
+
+func TestLocalPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := filepath.Join(root, "uploads")
+	store := NewLocal(dir, "/uploads")
+
+	if err := store.Put(ctx, "2026/logo.png", strings.NewReader("png"), 3, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	reader, err := store.Get(ctx, "2026/logo.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "png" {
+		t.Fatalf("Get = %q, want png", data)
+	}
+
+	// file sementara tidak tertinggal setelah rename
+	entries, err := os.ReadDir(filepath.Join(dir, "2026"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "logo.png" {
+		t.Fatalf("dir entries %v, want only logo.png", entries)
+	}
+
+	if err := store.Delete(ctx, "2026/logo.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "2026/logo.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after delete: %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "2026/logo.png"); err != nil {
+		t.Fatalf("Delete missing: %v", err)
+	}
+
+	// key yang keluar dari Dir ditolak dan tidak menulis apapun di luar Dir
+	if err := store.Put(ctx, "../escape.png", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Put escape: %v, want ErrInvalidKey", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.png")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("escape.png written outside dir: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory menyimpan object di memory proses, dipakai sebagai pengganti Local/S3 di test
+type Memory struct {
+	BaseURL string
+
+	mu      sync.RWMutex
+	objects map[string]MemoryObject
+}
+
+// MemoryObject adalah object yang disimpan Memory
+type MemoryObject struct {
+	Data        []byte
+	ContentType string
+}
+
+func NewMemory(baseURL string) *Memory {
+	return &Memory{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		objects: map[string]MemoryObject{},
+	}
+}
+
+func (s *Memory) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = MemoryObject{Data: data, ContentType: contentType}
+	return nil
+}
+
+func (s *Memory) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(object.Data)), nil
+}
+
+func (s *Memory) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *Memory) URL(key string) string {
+	return s.BaseURL + "/" + key
+}
+
+func (s *Memory) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if _, err := cleanKey(key); err != nil {
+		return "", err
+	}
+	return s.URL(key), nil
+}
+
+// Object mengembalikan object yang tersimpan di key, untuk assertion di test
+func (s *Memory) Object(key string) (MemoryObject, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[key]
+	return object, ok
+}
+
+// Keys mengembalikan semua key yang tersimpan
+func (s *Memory) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 adalah server S3 minimal (path-style) untuk PUT, GET, HEAD dan DELETE object
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeS3Object
+}
+
+type fakeS3Object struct {
+	data        []byte
+	contentType string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data = decodeAWSChunked(data)
+		}
+		f.objects[path] = fakeS3Object{data: data, contentType: r.Header.Get("Content-Type")}
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeAWSChunked membaca body aws-chunked yang dikirim client lewat HTTP tanpa TLS
+func decodeAWSChunked(body []byte) []byte {
+	data := make([]byte, 0, len(body))
+	for {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			return data
+		}
+		sizeHex, _, _ := strings.Cut(string(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 || int(size) > len(rest) {
+			return data
+		}
+		data = append(data, rest[:size]...)
+		body = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+}
+
+func newTestS3(t *testing.T) (*S3, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{objects: map[string]fakeS3Object{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	// Region diisi supaya client tidak meminta lokasi bucket ke server
+	s3, err := NewS3(S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "panel",
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s3, fake
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	s3, fake := newTestS3(t)
+
+	if err := s3.Put(ctx, "2026/a.png", strings.NewReader("png"), 3, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if object, ok := fake.objects["panel/2026/a.png"]; !ok || string(object.data) != "png" || object.contentType != "image/png" {
+		t.Fatalf("stored %+v, %v; want png in bucket panel", object, ok)
+	}
+
+	reader, err := s3.Get(ctx, "2026/a.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "png" {
+		t.Fatalf("Get = %q, want png", data)
+	}
+
+	if err := s3.Delete(ctx, "2026/a.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s3.Get(ctx, "2026/a.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after delete: %v, want ErrNotFound", err)
+	}
+
+	// key tidak valid ditolak sebelum request ke server
+	if err := s3.Put(ctx, "../a.png", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Put invalid key: %v, want ErrInvalidKey", err)
+	}
+	if _, err := s3.Get(ctx, "/etc/passwd"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Get invalid key: %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestS3URL(t *testing.T) {
+	ctx := context.Background()
+	s3, _ := newTestS3(t)
+
+	if got := s3.URL("2026/a.png"); !strings.HasSuffix(got, "/panel/2026/a.png") || !strings.HasPrefix(got, "http://127.0.0.1:") {
+		t.Fatalf("URL = %q, want endpoint/bucket/key", got)
+	}
+
+	signed, err := s3.SignedURL(ctx, "2026/a.png", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil || u.Path != "/panel/2026/a.png" || u.Query().Get("X-Amz-Signature") == "" || u.Query().Get("X-Amz-Expires") != "60" {
+		t.Fatalf("SignedURL = %q, want presigned url for 60s", signed)
+	}
+
+	custom, err := NewS3(S3Config{Endpoint: "s3.example.com", Bucket: "panel", PublicURL: "https://cdn.example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := custom.URL("2026/a.png"); got != "https://cdn.example.com/2026/a.png" {
+		t.Fatalf("URL with PublicURL = %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound dikembalikan Get jika object tidak ada
+	ErrNotFound = errors.New("storage object not found")
+	// ErrInvalidKey dikembalikan jika key kosong atau keluar dari root (mis. "../")
+	ErrInvalidKey = errors.New("storage key is invalid")
+)
+
+// Storage adalah tempat menyimpan file upload. Key berbentuk path relatif
+// dengan pemisah "/", mis. "2026/10/19/logo.png"
+type Storage interface {
+	// Put menyimpan isi r ke key, size -1 jika ukuran belum diketahui
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get membuka object, ErrNotFound jika tidak ada
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete menghapus object, tidak error jika object sudah tidak ada
+	Delete(ctx context.Context, key string) error
+	// URL adalah URL publik object
+	URL(key string) string
+	// SignedURL adalah URL sementara untuk object yang tidak publik
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// KeyOf mengambil key dari URL yang dibuat s.URL. Jika s.URL relatif (/uploads/...),
+// URL yang disimpan dengan host request dikenali hanya jika diawali baseURL (scheme://host
+// request), URL dengan host lain tidak dianggap milik storage ini
+func KeyOf(s Storage, fileURL string, baseURL string) (string, bool) {
+	prefix := s.URL("")
+	if key, ok := strings.CutPrefix(fileURL, prefix); ok {
+		return key, key != ""
+	}
+
+	if strings.HasPrefix(prefix, "/") && baseURL != "" {
+		if key, ok := strings.CutPrefix(fileURL, strings.TrimRight(baseURL, "/")+prefix); ok {
+			return key, key != ""
+		}
+	}
+	return "", false
+}
+
+// cleanKey menolak key kosong, absolut atau yang keluar dari root
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean(key)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"logo.png", "logo.png", false},
+		{"2026/10/19/logo.png", "2026/10/19/logo.png", false},
+		{"a/./b/../logo.png", "a/logo.png", false},
+		{"", "", true},
+		{".", "", true},
+		{"..", "", true},
+		{"../logo.png", "", true},
+		{"a/../../logo.png", "", true},
+		{"/etc/passwd", "", true},
+		{"a\\..\\logo.png", "", true},
+	}
+	for _, tt := range tests {
+		got, err := cleanKey(tt.key)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("cleanKey(%q) error = %v, want ErrInvalidKey", tt.key, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("cleanKey(%q) = %q, %v; want %q", tt.key, got, err, tt.want)
+		}
+	}
+}
+
+func TestMemoryPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory("https://cdn.example.com/uploads/")
+
+	if err := store.Put(ctx, "logo/a.png", strings.NewReader("png"), 3, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	object, ok := store.Object("logo/a.png")
+	if !ok || object.ContentType != "image/png" {
+		t.Fatalf("Object = %+v, %v; want image/png", object, ok)
+	}
+
+	reader, err := store.Get(ctx, "logo/a.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "png" {
+		t.Fatalf("Get = %q, want png", data)
+	}
+
+	if err := store.Delete(ctx, "logo/a.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "logo/a.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after delete: %v, want ErrNotFound", err)
+	}
+	// hapus object yang sudah tidak ada tidak error
+	if err := store.Delete(ctx, "logo/a.png"); err != nil {
+		t.Fatalf("Delete missing: %v", err)
+	}
+
+	if err := store.Put(ctx, "../a.png", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Put invalid key: %v, want ErrInvalidKey", err)
+	}
+	if len(store.Keys()) != 0 {
+		t.Fatalf("Keys = %v, want empty", store.Keys())
+	}
+}
+
+func TestKeyOf(t *testing.T) {
+	s3, err := NewS3(S3Config{Endpoint: "s3.example.com", Bucket: "panel", UseSSL: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		store   Storage
+		fileURL string
+		baseURL string
+		want    string
+		wantOK  bool
+	}{
+		{"absolute base url", NewMemory("https://cdn.example.com/uploads"), "https://cdn.example.com/uploads/2026/a.png", "", "2026/a.png", true},
+		{"other host", NewMemory("https://cdn.example.com/uploads"), "https://other.example.com/uploads/2026/a.png", "https://other.example.com", "", false},
+		{"prefix only", NewMemory("https://cdn.example.com/uploads"), "https://cdn.example.com/uploads/", "", "", false},
+		{"relative base url", NewLocal("uploads", "/uploads"), "/uploads/2026/a.png", "", "2026/a.png", true},
+		// URL relatif yang disimpan dengan host request dikenali hanya untuk host request tersebut
+		{"relative base url with host", NewLocal("uploads", "/uploads"), "http://localhost:1903/uploads/2026/a.png", "http://localhost:1903", "2026/a.png", true},
+		{"relative base url trailing slash", NewLocal("uploads", "/uploads"), "http://localhost:1903/uploads/2026/a.png", "http://localhost:1903/", "2026/a.png", true},
+		{"relative base url foreign host", NewLocal("uploads", "/uploads"), "https://evil.example.com/uploads/2026/a.png", "http://localhost:1903", "", false},
+		{"relative base url without request host", NewLocal("uploads", "/uploads"), "http://localhost:1903/uploads/2026/a.png", "", "", false},
+		{"relative base url other path", NewLocal("uploads", "/uploads"), "http://localhost:1903/static/a.png", "http://localhost:1903", "", false},
+		{"s3 default public url", s3, "https://s3.example.com/panel/2026/a.png", "", "2026/a.png", true},
+		{"s3 other bucket", s3, "https://s3.example.com/other/2026/a.png", "", "", false},
+		{"s3 other host", s3, "https://evil.example.com/panel/2026/a.png", "https://evil.example.com", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := KeyOf(tt.store, tt.fileURL, tt.baseURL)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: KeyOf(%q) = %q, %v; want %q, %v", tt.name, tt.fileURL, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
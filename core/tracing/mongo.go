@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type mongoSpanKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// MongoSpans menyimpan span yang sedang berjalan untuk setiap command MongoDB,
+// dipanggil dari CommandMonitor karena started & succeeded/failed adalah event terpisah
+type MongoSpans struct {
+	spans sync.Map
+}
+
+func NewMongoSpans() *MongoSpans {
+	return &MongoSpans{}
+}
+
+func (m *MongoSpans) Started(ctx context.Context, evt *event.CommandStartedEvent) {
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return
+	}
+
+	_, span := Tracer().Start(ctx, "mongo."+evt.CommandName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameMongoDB,
+			semconv.DBNamespace(evt.DatabaseName),
+			semconv.DBOperationName(evt.CommandName),
+		),
+	)
+	m.spans.Store(mongoSpanKey{evt.ConnectionID, evt.RequestID}, span)
+}
+
+func (m *MongoSpans) Succeeded(evt *event.CommandSucceededEvent) {
+	if span, ok := m.load(evt.ConnectionID, evt.RequestID); ok {
+		span.End()
+	}
+}
+
+func (m *MongoSpans) Failed(evt *event.CommandFailedEvent) {
+	if span, ok := m.load(evt.ConnectionID, evt.RequestID); ok {
+		span.RecordError(errors.New(evt.Failure))
+		span.SetStatus(codes.Error, evt.Failure)
+		span.End()
+	}
+}
+
+func (m *MongoSpans) load(connectionID string, requestID int64) (trace.Span, bool) {
+	value, ok := m.spans.LoadAndDelete(mongoSpanKey{connectionID, requestID})
+	if !ok {
+		return nil, false
+	}
+	return value.(trace.Span), true
+}
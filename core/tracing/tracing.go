@@ -0,0 +1,14 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "backend"
+
+// Tracer mengambil tracer dari global provider. Jika tracing tidak diaktifkan,
+// provider global adalah noop sehingga span tidak dicatat sama sekali
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-sql-driver/mysql"
+	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+	"gorm.io/gorm"
+)
+
+// Kode error yang dibaca frontend, nilainya tidak boleh diubah
+const (
+	ErrCodeBadRequest           = "BAD_REQUEST"
+	ErrCodeUnauthorized         = "UNAUTHORIZED"
+	ErrCodeForbidden            = "FORBIDDEN"
+	ErrCodeNotFound             = "NOT_FOUND"
+	ErrCodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	ErrCodeConflict             = "CONFLICT"
+	ErrCodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	ErrCodePreconditionFailed   = "PRECONDITION_FAILED"
+	ErrCodePreconditionRequired = "PRECONDITION_REQUIRED"
+	ErrCodeValidation           = "VALIDATION_FAILED"
+	ErrCodeDuplicateKey         = "DUPLICATE_KEY"
+	ErrCodeForeignKey           = "FOREIGN_KEY_VIOLATION"
+	ErrCodeTooManyRequests      = "TOO_MANY_REQUESTS"
+	ErrCodeTooManyAttempts      = "TOO_MANY_ATTEMPTS"
+	ErrCodeInternal             = "INTERNAL_ERROR"
+
+	ErrCodeIdempotencyReused     = "IDEMPOTENCY_KEY_REUSED"
+	ErrCodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+)
+
+// kode error MySQL yang dipetakan ke response 4xx
+const (
+	mysqlDuplicateEntry     = 1062
+	mysqlRowIsReferenced    = 1451
+	mysqlNoReferencedRow    = 1452
+	mysqlRowIsReferencedOld = 1217
+	mysqlNoReferencedRowOld = 1216
+)
+
+// AppError adalah error yang dikembalikan service dan diubah oleh ErrorHandler
+// menjadi ErrorResponse. Err berisi penyebab asli dan tidak pernah dikirim ke client
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	Details []FieldError
+	Err     error
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ErrorResponse struct {
+	Status  bool         `json:"status"`
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Error   string       `json:"error"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+func NewAppError(status int, code string, message string) *AppError {
+	return &AppError{Status: status, Code: code, Message: message}
+}
+
+// WrapAppError membuat AppError dengan penyebab asli untuk keperluan log
+func WrapAppError(err error, status int, code string, message string) *AppError {
+	return &AppError{Status: status, Code: code, Message: message, Err: err}
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	if e.Message != "" {
+		return e.Code + ": " + e.Message
+	}
+	return e.Code
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// ToAppError mengubah error apapun menjadi AppError: AppError diteruskan,
+// error validator menjadi 422, fiber.Error dipetakan per status, error MySQL duplicate/foreign key menjadi 409/422,
+// record not found menjadi 404, sisanya 500
+func ToAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return &AppError{Status: fiber.StatusUnprocessableEntity, Code: ErrCodeValidation, Err: err}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		message := ""
+		if fiberErr.Message != fiberutils.StatusMessage(fiberErr.Code) {
+			message = fiberErr.Message
+		}
+		return &AppError{Status: fiberErr.Code, Code: codeFromStatus(fiberErr.Code), Message: message, Err: err}
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &AppError{Status: fiber.StatusNotFound, Code: ErrCodeNotFound, Err: err}
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlDuplicateEntry:
+			appErr := &AppError{Status: fiber.StatusConflict, Code: ErrCodeDuplicateKey, Err: err}
+			if field := duplicateKeyField(mysqlErr.Message); field != "" {
+				appErr.Details = []FieldError{{Field: field}}
+			}
+			return appErr
+		case mysqlRowIsReferenced, mysqlNoReferencedRow, mysqlRowIsReferencedOld, mysqlNoReferencedRowOld:
+			return &AppError{Status: fiber.StatusUnprocessableEntity, Code: ErrCodeForeignKey, Err: err}
+		}
+	}
+
+	return &AppError{Status: fiber.StatusInternalServerError, Code: ErrCodeInternal, Err: err}
+}
+
+func codeFromStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return ErrCodeBadRequest
+	case fiber.StatusUnauthorized:
+		return ErrCodeUnauthorized
+	case fiber.StatusForbidden:
+		return ErrCodeForbidden
+	case fiber.StatusNotFound:
+		return ErrCodeNotFound
+	case fiber.StatusMethodNotAllowed:
+		return ErrCodeMethodNotAllowed
+	case fiber.StatusConflict:
+		return ErrCodeConflict
+	case fiber.StatusRequestEntityTooLarge:
+		return ErrCodePayloadTooLarge
+	case fiber.StatusPreconditionFailed:
+		return ErrCodePreconditionFailed
+	case fiber.StatusPreconditionRequired:
+		return ErrCodePreconditionRequired
+	case fiber.StatusUnprocessableEntity:
+		return ErrCodeValidation
+	case fiber.StatusTooManyRequests:
+		return ErrCodeTooManyRequests
+	}
+	if status >= fiber.StatusInternalServerError {
+		return ErrCodeInternal
+	}
+	return ErrCodeBadRequest
+}
+
+var duplicateKeyPattern = regexp.MustCompile(`for key '([^']+)'`)
+
+// duplicateKeyField mengambil nama key dari pesan "Duplicate entry 'x' for key 'branchs.PRIMARY'"
+func duplicateKeyField(message string) string {
+	match := duplicateKeyPattern.FindStringSubmatch(message)
+	if len(match) != 2 {
+		return ""
+	}
+
+	key := match[1]
+	if idx := strings.LastIndex(key, "."); idx != -1 {
+		key = key[idx+1:]
+	}
+	if key == "PRIMARY" {
+		return "id"
+	}
+	return key
+}
+
+var errorMessages = map[string]map[string]string{
+	ErrCodeBadRequest:           {"id": "Request tidak valid", "en": "Invalid request"},
+	ErrCodeUnauthorized:         {"id": "Tidak terautentikasi", "en": "Unauthorized"},
+	ErrCodeForbidden:            {"id": "Tidak memiliki akses", "en": "Forbidden"},
+	ErrCodeNotFound:             {"id": "Data tidak ditemukan", "en": "Resource not found"},
+	ErrCodeMethodNotAllowed:     {"id": "Method tidak diizinkan", "en": "Method not allowed"},
+	ErrCodeConflict:             {"id": "Data bentrok dengan data lain", "en": "Conflict with existing data"},
+	ErrCodePayloadTooLarge:      {"id": "Ukuran request terlalu besar", "en": "Payload too large"},
+	ErrCodePreconditionFailed:   {"id": "Data sudah diubah pengguna lain, muat ulang lalu coba lagi", "en": "Resource was modified by someone else, reload and try again"},
+	ErrCodePreconditionRequired: {"id": "Header If-Match wajib diisi", "en": "If-Match header is required"},
+	ErrCodeValidation:           {"id": "Validasi gagal", "en": "Validation failed"},
+	ErrCodeDuplicateKey:         {"id": "Data sudah ada", "en": "Resource already exists"},
+	ErrCodeForeignKey:           {"id": "Data masih berelasi dengan data lain", "en": "Resource is referenced by or references missing data"},
+	ErrCodeTooManyRequests:      {"id": "Terlalu banyak request, coba lagi nanti", "en": "Too many requests, try again later"},
+	ErrCodeTooManyAttempts:      {"id": "Terlalu banyak percobaan gagal, coba lagi nanti", "en": "Too many failed attempts, try again later"},
+	ErrCodeInternal:             {"id": "Terjadi kesalahan pada server", "en": "Internal server error"},
+
+	ErrCodeIdempotencyReused:     {"id": "Idempotency-Key sudah dipakai untuk request yang berbeda", "en": "Idempotency-Key was already used for a different request"},
+	ErrCodeIdempotencyInProgress: {"id": "Request dengan Idempotency-Key ini masih diproses", "en": "A request with this Idempotency-Key is still being processed"},
+}
+
+// ErrorMessage mengembalikan pesan default untuk kode error dalam bahasa lang ("id" atau "en"),
+// bahasa Indonesia dipakai jika lang kosong atau tidak dikenal
+func ErrorMessage(code string, lang string) string {
+	messages, ok := errorMessages[code]
+	if !ok {
+		messages = errorMessages[ErrCodeInternal]
+	}
+	if message, ok := messages[lang]; ok {
+		return message
+	}
+	return messages["id"]
+}
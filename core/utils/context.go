@@ -0,0 +1,21 @@
+package utils
+
+import "context"
+
+type contextKey string
+
+const requestIDKey contextKey = "request_id"
+
+// WithRequestID menyimpan request id ke dalam context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext mengambil request id dari context, string kosong jika tidak ada
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
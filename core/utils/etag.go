@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// VersionETag membuat ETag dari kolom version, mis. version 3 -> "3"
+func VersionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseVersionETag membaca version dari header If-Match / If-None-Match.
+// Weak ETag (W/"3") juga diterima, false jika format tidak dikenal
+func ParseVersionETag(header string) (int64, bool) {
+	value := strings.TrimSpace(header)
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // decoder gif untuk image.Decode
+	"image/jpeg"
+	"image/png"
+
+	"github.com/gabriel-vasile/mimetype"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // decoder webp untuk image.Decode
+)
+
+// batas jumlah pixel sebelum gambar di-decode, mencegah decompression bomb
+// (file kecil yang mengembang jadi gigabyte di memory)
+const maxImagePixels = 40_000_000
+
+const jpegQuality = 90
+
+var errImageTooLarge = errors.New("dimensi gambar terlalu besar")
+
+// processedFile adalah isi file yang akan disimpan, gambar sudah di-encode ulang
+type processedFile struct {
+	data        []byte
+	ext         string
+	contentType string
+	thumbnail   []byte
+}
+
+// isNormalizableImage bernilai true untuk format gambar yang bisa di-decode dan di-encode ulang
+func isNormalizableImage(mtype *mimetype.MIME) bool {
+	for _, name := range []string{"image/jpeg", "image/png", "image/gif", "image/webp"} {
+		if mtype.Is(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeImage men-decode gambar, memutar sesuai orientasi EXIF lalu meng-encode ulang.
+// Encode ulang sekaligus membuang semua metadata (EXIF, GPS, komentar) dan payload
+// yang diselipkan di file. JPEG tetap JPEG, format lain menjadi PNG supaya transparansi tidak hilang
+func normalizeImage(data []byte, mtype *mimetype.MIME, thumbnailSize int) (*processedFile, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, errImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	isJPEG := mtype.Is("image/jpeg")
+	if isJPEG {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	encode := func(img image.Image) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		if isJPEG {
+			err := jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality})
+			return buf.Bytes(), err
+		}
+		err := png.Encode(buf, img)
+		return buf.Bytes(), err
+	}
+
+	result := &processedFile{ext: ".png", contentType: "image/png"}
+	if isJPEG {
+		result.ext, result.contentType = ".jpg", "image/jpeg"
+	}
+
+	if result.data, err = encode(img); err != nil {
+		return nil, err
+	}
+	if thumbnailSize > 0 {
+		if result.thumbnail, err = encode(thumbnail(img, thumbnailSize)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// thumbnail mengecilkan gambar sehingga sisi terpanjang maksimal size pixel,
+// gambar yang sudah lebih kecil tidak diperbesar
+func thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segment EXIF APP1,
+// 1 (normal) jika tidak ada atau tidak terbaca
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		// SOS: setelah ini data gambar, EXIF selalu ada sebelumnya
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// applyOrientation memutar/membalik gambar sesuai nilai Orientation EXIF 1-8
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontal
+				sx, sy = w-1-x, y
+			case 3: // putar 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // flip vertikal
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // putar 90 searah jarum jam
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // putar 90 berlawanan jarum jam
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.SetNRGBA(x, y, src.NRGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var (
+	testRed  = color.NRGBA{R: 255, A: 255}
+	testBlue = color.NRGBA{B: 255, A: 255}
+)
+
+// halfImage membuat gambar w x h dengan setengah kiri merah dan setengah kanan biru
+func halfImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.SetNRGBA(x, y, testRed)
+			} else {
+				img.SetNRGBA(x, y, testBlue)
+			}
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withExifOrientation menyisipkan segment APP1 EXIF berisi tag Orientation setelah SOI
+func withExifOrientation(data []byte, orientation uint16) []byte {
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("MM")
+	binary.Write(tiff, binary.BigEndian, uint16(42))
+	binary.Write(tiff, binary.BigEndian, uint32(8))
+	binary.Write(tiff, binary.BigEndian, uint16(1))
+	binary.Write(tiff, binary.BigEndian, uint16(0x0112)) // Orientation
+	binary.Write(tiff, binary.BigEndian, uint16(3))      // SHORT
+	binary.Write(tiff, binary.BigEndian, uint32(1))
+	binary.Write(tiff, binary.BigEndian, orientation)
+	binary.Write(tiff, binary.BigEndian, uint16(0))
+	binary.Write(tiff, binary.BigEndian, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+// withPNGSize mengganti dimensi di chunk IHDR tanpa mengubah data pixel
+func withPNGSize(data []byte, width, height uint32) []byte {
+	out := append([]byte{}, data...)
+	binary.BigEndian.PutUint32(out[16:], width)
+	binary.BigEndian.PutUint32(out[20:], height)
+	binary.BigEndian.PutUint32(out[29:], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}
+
+func isColor(c color.Color, want color.NRGBA) bool {
+	r, g, b, _ := c.RGBA()
+	near := func(v uint32, w uint8) bool {
+		diff := int(v>>8) - int(w)
+		return diff > -40 && diff < 40
+	}
+	return near(r, want.R) && near(g, want.G) && near(b, want.B)
+}
+
+func TestJpegOrientation(t *testing.T) {
+	data := encodeJPEG(t, halfImage(8, 8))
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		if got := jpegOrientation(withExifOrientation(data, orientation)); got != int(orientation) {
+			t.Errorf("orientation %d: got %d", orientation, got)
+		}
+	}
+	// tanpa EXIF atau nilai di luar 1-8 dianggap normal
+	if got := jpegOrientation(data); got != 1 {
+		t.Errorf("no exif: got %d, want 1", got)
+	}
+	if got := jpegOrientation(withExifOrientation(data, 9)); got != 1 {
+		t.Errorf("invalid orientation: got %d, want 1", got)
+	}
+	if got := jpegOrientation([]byte("not a jpeg")); got != 1 {
+		t.Errorf("not jpeg: got %d, want 1", got)
+	}
+}
+
+func TestNormalizeImageExifRotation(t *testing.T) {
+	// 32x16, kiri merah kanan biru, EXIF 6 berarti harus diputar 90 derajat searah jarum jam
+	data := withExifOrientation(encodeJPEG(t, halfImage(32, 16)), 6)
+
+	result, err := normalizeImage(data, mimetype.Detect(data), 0)
+	if err != nil {
+		t.Fatalf("normalizeImage: %v", err)
+	}
+	if result.ext != ".jpg" || result.contentType != "image/jpeg" {
+		t.Fatalf("got %s %s, want jpeg", result.ext, result.contentType)
+	}
+	if bytes.Contains(result.data, []byte("Exif")) {
+		t.Fatal("EXIF not stripped")
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(result.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 16 || bounds.Dy() != 32 {
+		t.Fatalf("size %dx%d, want 16x32", bounds.Dx(), bounds.Dy())
+	}
+	// setengah kiri (merah) menjadi setengah atas
+	if !isColor(img.At(8, 4), testRed) || !isColor(img.At(8, 28), testBlue) {
+		t.Fatalf("top %v bottom %v, want red on top and blue below", img.At(8, 4), img.At(8, 28))
+	}
+}
+
+func TestNormalizeImageReencode(t *testing.T) {
+	// payload yang diselipkan setelah IEND ikut terbuang saat encode ulang
+	data := append(encodePNG(t, halfImage(40, 20)), []byte("<?php echo 1; ?>")...)
+
+	result, err := normalizeImage(data, mimetype.Detect(data), 10)
+	if err != nil {
+		t.Fatalf("normalizeImage: %v", err)
+	}
+	if result.ext != ".png" || bytes.Contains(result.data, []byte("<?php")) {
+		t.Fatalf("ext %s, payload kept %v; want clean png", result.ext, bytes.Contains(result.data, []byte("<?php")))
+	}
+
+	img, err := png.Decode(bytes.NewReader(result.data))
+	if err != nil || img.Bounds().Dx() != 40 || img.Bounds().Dy() != 20 {
+		t.Fatalf("decoded %v, %v; want 40x20", img.Bounds(), err)
+	}
+
+	// thumbnail mengikuti sisi terpanjang dan menjaga rasio
+	thumb, err := png.Decode(bytes.NewReader(result.thumbnail))
+	if err != nil || thumb.Bounds().Dx() != 10 || thumb.Bounds().Dy() != 5 {
+		t.Fatalf("thumbnail %v, %v; want 10x5", thumb.Bounds(), err)
+	}
+}
+
+func TestNormalizeImageTooLarge(t *testing.T) {
+	// file kecil yang mengaku 10000x10000 ditolak sebelum pixel di-decode
+	data := withPNGSize(encodePNG(t, halfImage(2, 2)), 10000, 10000)
+
+	if _, err := normalizeImage(data, mimetype.Detect(data), 0); !errors.Is(err, errImageTooLarge) {
+		t.Fatalf("got %v, want errImageTooLarge", err)
+	}
+}
+
+func TestThumbnailNotUpscaled(t *testing.T) {
+	img := halfImage(8, 4)
+	if got := thumbnail(img, 100); got != image.Image(img) {
+		t.Fatalf("small image resized to %v", got.Bounds())
+	}
+}
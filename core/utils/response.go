@@ -1,12 +1,13 @@
 package utils
 
 type WebResponse[T any] struct {
-	Status  bool          `json:"status"`
-	Message string        `json:"message,omitempty"`
-	Code    int           `json:"code,omitempty"`
-	Data    T             `json:"resource"`
-	Paging  *PageMetadata `json:"meta,omitempty"`
-	Errors  string        `json:"errors,omitempty"`
+	Status  bool            `json:"status"`
+	Message string          `json:"message,omitempty"`
+	Code    int             `json:"code,omitempty"`
+	Data    T               `json:"resource"`
+	Paging  *PageMetadata   `json:"meta,omitempty"`
+	Cursor  *CursorMetadata `json:"cursor,omitempty"`
+	Errors  string          `json:"errors,omitempty"`
 }
 
 type PageResponse[T any] struct {
@@ -37,3 +38,23 @@ func NewPageMetadata(page, limit int, total int64) *PageMetadata {
 		TotalPage: totalPages,
 	}
 }
+
+// CursorMetadata dipakai list endpoint dengan pagination cursor (keyset) sebagai
+// pengganti PageMetadata, Next/Prev kosong jika tidak ada halaman lagi
+type CursorMetadata struct {
+	Size int    `json:"size"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+func NewCursorMetadata(limit int, next, prev string) *CursorMetadata {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	return &CursorMetadata{
+		Size: limit,
+		Next: next,
+		Prev: prev,
+	}
+}
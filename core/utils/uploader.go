@@ -1,134 +1,202 @@
 package utils
 
 import (
+	"backend/core/storage"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"net/http"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
-	"time"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
 )
 
+// UploadsRoute adalah prefix URL tempat file upload disajikan oleh NewFiber (driver local)
+const UploadsRoute = "/uploads"
+
+// ErrFileRejected membungkus error karena file dari client tidak memenuhi aturan upload
+var ErrFileRejected = errors.New("file ditolak")
+
 type UploadConfig struct {
-	UploadDir     string
 	AllowedExts   []string
 	MaxFileSizeMB int64
-	BaseURL       string
+	// ThumbnailSize adalah sisi terpanjang thumbnail gambar dalam pixel, 0 berarti tanpa thumbnail
+	ThumbnailSize int
 }
 
 type UploadResult struct {
-	FileName string
-	Path     string
-	Size     int64
-	URL      string
+	FileName     string
+	Key          string
+	Size         int64
+	URL          string
+	ContentType  string
+	ThumbnailKey string
+	ThumbnailURL string
 }
 
-type fileResult struct {
-	result UploadResult
-	err    error
-}
-
-func UploadFiles(r *http.Request, files []*multipart.FileHeader, config UploadConfig) ([]UploadResult, error) {
+// UploadFiles menyimpan files ke store secara paralel. Tipe file dideteksi dari isinya
+// (bukan ekstensi), gambar di-encode ulang tanpa metadata, dan nama file berupa hash isi
+// sehingga file yang sama hanya disimpan sekali. Jika satu file gagal, file lain yang
+// sudah ditulis batch ini dihapus lagi
+func UploadFiles(ctx context.Context, store storage.Storage, files []*multipart.FileHeader, config UploadConfig) ([]UploadResult, error) {
 	if len(files) == 0 {
 		return nil, errors.New("tidak ada file yang dikirim")
 	}
 
-	results := make([]UploadResult, 0, len(files))
-	resultCh := make(chan fileResult, len(files))
-
-	// Buat folder upload berdasarkan tanggal
-	dateFolder := time.Now().Format("2006/01/02")
-	uploadPath := filepath.Join(config.UploadDir, dateFolder)
-	if err := os.MkdirAll(uploadPath, os.ModePerm); err != nil {
-		return nil, err
+	results := make([]UploadResult, len(files))
+	created := make([][]string, len(files))
+	errs := make([]error, len(files))
+
+	// Mulai upload tiap file secara paralel, tunggu semua selesai sebelum rollback
+	// supaya tidak ada file yang ditulis setelah dihapus
+	var wg sync.WaitGroup
+	for i, fileHeader := range files {
+		wg.Add(1)
+		go func(i int, fh *multipart.FileHeader) {
+			defer wg.Done()
+			results[i], created[i], errs[i] = uploadFile(ctx, store, fh, config)
+		}(i, fileHeader)
 	}
+	wg.Wait()
 
-	// Tentukan scheme dan BaseURL jika kosong
-	scheme := "http"
-	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
-		scheme = "https"
-	}
-	if config.BaseURL == "" {
-		config.BaseURL = fmt.Sprintf("%s://%s/uploads", scheme, r.Host)
-	}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
 
-	// Mulai upload tiap file secara paralel
-	for _, fileHeader := range files {
-		go func(fh *multipart.FileHeader) {
-			ext := strings.ToLower(filepath.Ext(fh.Filename))
-			if !isAllowedExt(ext, config.AllowedExts) {
-				resultCh <- fileResult{err: fmt.Errorf("file %s memiliki ekstensi tidak diizinkan", fh.Filename)}
-				return
+		// rollback tetap jalan walau request sudah dibatalkan
+		cleanupCtx := context.WithoutCancel(ctx)
+		for _, keys := range created {
+			for _, key := range keys {
+				_ = store.Delete(cleanupCtx, key)
 			}
+		}
+		return nil, err
+	}
 
-			if fh.Size > config.MaxFileSizeMB*1024*1024 {
-				resultCh <- fileResult{err: fmt.Errorf("file %s melebihi batas %d MB", fh.Filename, config.MaxFileSizeMB)}
-				return
-			}
+	return results, nil
+}
 
-			file, err := fh.Open()
-			if err != nil {
-				resultCh <- fileResult{err: err}
-				return
-			}
-			defer file.Close()
+// uploadFile memproses dan menyimpan satu file, mengembalikan key yang baru dibuat
+// (bukan hasil deduplikasi) untuk keperluan rollback
+func uploadFile(ctx context.Context, store storage.Storage, fh *multipart.FileHeader, config UploadConfig) (UploadResult, []string, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return UploadResult{}, nil, err
+	}
+	defer file.Close()
 
-			newFileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
-			fullPath := filepath.Join(uploadPath, newFileName)
+	// ukuran dihitung dari byte yang benar-benar dibaca, bukan fh.Size dari client
+	maxBytes := config.MaxFileSizeMB * 1024 * 1024
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return UploadResult{}, nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return UploadResult{}, nil, fmt.Errorf("%w: file %s melebihi batas %d MB", ErrFileRejected, fh.Filename, config.MaxFileSizeMB)
+	}
 
-			out, err := os.Create(fullPath)
-			if err != nil {
-				resultCh <- fileResult{err: err}
-				return
-			}
+	mtype := mimetype.Detect(data)
+	if !isAllowedExt(mtype.Extension(), config.AllowedExts) {
+		return UploadResult{}, nil, fmt.Errorf("%w: file %s bertipe %s yang tidak diizinkan", ErrFileRejected, fh.Filename, mtype.String())
+	}
 
-			size, err := io.Copy(out, file)
-			out.Close()
-			if err != nil {
-				resultCh <- fileResult{err: err}
-				return
-			}
+	processed := &processedFile{data: data, ext: mtype.Extension(), contentType: mtype.String()}
+	if isNormalizableImage(mtype) {
+		processed, err = normalizeImage(data, mtype, config.ThumbnailSize)
+		if err != nil {
+			return UploadResult{}, nil, fmt.Errorf("%w: file %s bukan gambar yang valid: %v", ErrFileRejected, fh.Filename, err)
+		}
+	}
 
-			publicURL := fmt.Sprintf("%s/%s/%s", strings.TrimRight(config.BaseURL, "/"), dateFolder, newFileName)
+	sum := sha256.Sum256(processed.data)
+	hash := hex.EncodeToString(sum[:])
+	fileName := hash + processed.ext
+	result := UploadResult{
+		FileName:    fileName,
+		Key:         path.Join(hash[:2], fileName),
+		Size:        int64(len(processed.data)),
+		ContentType: processed.contentType,
+	}
 
-			resultCh <- fileResult{
-				result: UploadResult{
-					FileName: newFileName,
-					Path:     fullPath,
-					Size:     size,
-					URL:      publicURL,
-				},
-			}
-		}(fileHeader)
+	created := make([]string, 0, 2)
+	isNew, err := putIfAbsent(ctx, store, result.Key, processed.data, processed.contentType)
+	if err != nil {
+		return UploadResult{}, created, err
+	}
+	if isNew {
+		created = append(created, result.Key)
 	}
+	result.URL = store.URL(result.Key)
 
-	// Kumpulkan hasil
-	for i := 0; i < len(files); i++ {
-		res := <-resultCh
-		if res.err != nil {
-			return nil, res.err // hentikan jika ada error satu saja
+	if processed.thumbnail != nil {
+		result.ThumbnailKey = path.Join(hash[:2], hash+"_thumb"+processed.ext)
+		isNew, err := putIfAbsent(ctx, store, result.ThumbnailKey, processed.thumbnail, processed.contentType)
+		if err != nil {
+			return UploadResult{}, created, err
 		}
-		results = append(results, res.result)
+		if isNew {
+			created = append(created, result.ThumbnailKey)
+		}
+		result.ThumbnailURL = store.URL(result.ThumbnailKey)
 	}
 
-	close(resultCh)
+	return result, created, nil
+}
 
-	if len(results) == 0 {
-		return nil, errors.New("tidak ada file yang berhasil diupload")
+// putIfAbsent hanya menulis jika key belum ada, isi yang sama selalu punya key yang sama
+func putIfAbsent(ctx context.Context, store storage.Storage, key string, data []byte, contentType string) (bool, error) {
+	existing, err := store.Get(ctx, key)
+	if err == nil {
+		existing.Close()
+		return false, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return false, err
 	}
 
-	return results, nil
+	if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
+// DeleteUploadedFile menghapus file berdasarkan URL yang dibuat UploadFiles,
+// URL yang bukan milik store diabaikan. baseURL adalah host request untuk URL relatif
+func DeleteUploadedFile(ctx context.Context, store storage.Storage, fileURL string, baseURL string) error {
+	key, ok := storage.KeyOf(store, fileURL, baseURL)
+	if !ok {
+		return nil
+	}
+
+	err := store.Delete(ctx, key)
+	if errors.Is(err, storage.ErrInvalidKey) {
+		return nil
+	}
+	return err
+}
+
+// isAllowedExt mencocokkan ekstensi hasil deteksi isi file, .jpeg dan .jpg dianggap sama
 func isAllowedExt(ext string, allowed []string) bool {
-	ext = strings.ToLower(ext)
+	ext = normalizeExt(ext)
 	for _, a := range allowed {
-		if ext == strings.ToLower(a) {
+		if ext == normalizeExt(a) {
 			return true
 		}
 	}
 	return false
 }
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if ext == ".jpeg" {
+		return ".jpg"
+	}
+	return ext
+}
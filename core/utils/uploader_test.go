@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"backend/core/storage"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type testFile struct {
+	name string
+	data []byte
+}
+
+// fileHeaders membuat multipart.FileHeader seperti yang diterima controller dari c.MultipartForm
+func fileHeaders(t *testing.T, files ...testFile) []*multipart.FileHeader {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for _, file := range files {
+		part, err := writer.CreateFormFile("files", file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(file.data)
+	}
+	writer.Close()
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(10 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["files"]
+}
+
+// failingStorage gagal menulis object dengan content type tertentu
+type failingStorage struct {
+	*storage.Memory
+	failContentType string
+}
+
+func (s *failingStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if contentType == s.failContentType {
+		return errors.New("storage down")
+	}
+	return s.Memory.Put(ctx, key, r, size, contentType)
+}
+
+var testUploadConfig = UploadConfig{AllowedExts: []string{".jpg", ".png", ".txt"}, MaxFileSizeMB: 1, ThumbnailSize: 8}
+
+func TestUploadFiles(t *testing.T) {
+	store := storage.NewMemory("https://cdn.example.com/uploads")
+	png := encodePNG(t, halfImage(16, 16))
+
+	results, err := UploadFiles(context.Background(), store, fileHeaders(t,
+		testFile{"logo.png", png},
+		testFile{"copy.png", png},
+		testFile{"note.txt", []byte("hello")},
+	), testUploadConfig)
+	if err != nil {
+		t.Fatalf("UploadFiles: %v", err)
+	}
+
+	// isi yang sama menghasilkan key yang sama
+	if results[0].Key != results[1].Key || results[0].ThumbnailKey == "" {
+		t.Fatalf("results %+v, want same key with thumbnail", results[:2])
+	}
+	if !strings.HasPrefix(results[0].URL, "https://cdn.example.com/uploads/") || results[0].ContentType != "image/png" {
+		t.Fatalf("result %+v", results[0])
+	}
+	if results[2].ContentType != "text/plain; charset=utf-8" || results[2].ThumbnailKey != "" {
+		t.Fatalf("text result %+v", results[2])
+	}
+
+	keys := store.Keys()
+	sort.Strings(keys)
+	if len(keys) != 3 {
+		t.Fatalf("stored %v, want image, thumbnail and text", keys)
+	}
+}
+
+func TestUploadFilesRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		file testFile
+	}{
+		// tipe dideteksi dari isi, bukan ekstensi nama file
+		{"disallowed content", testFile{"fake.png", []byte("%PDF-1.4 not an image")}},
+		{"oversized file", testFile{"big.txt", bytes.Repeat([]byte("a"), 1<<20+1)}},
+		{"oversized image", testFile{"bomb.png", withPNGSize(encodePNG(t, halfImage(2, 2)), 10000, 10000)}},
+		{"broken image", testFile{"broken.jpg", encodeJPEG(t, halfImage(8, 8))[:100]}},
+	}
+	for _, tt := range tests {
+		store := storage.NewMemory("/uploads")
+		_, err := UploadFiles(context.Background(), store, fileHeaders(t, tt.file), testUploadConfig)
+		if !errors.Is(err, ErrFileRejected) {
+			t.Errorf("%s: got %v, want ErrFileRejected", tt.name, err)
+		}
+		if keys := store.Keys(); len(keys) != 0 {
+			t.Errorf("%s: stored %v, want nothing", tt.name, keys)
+		}
+	}
+}
+
+func TestUploadFilesRollback(t *testing.T) {
+	png := encodePNG(t, halfImage(16, 16))
+	existing := encodePNG(t, halfImage(4, 4))
+	ctx := context.Background()
+
+	t.Run("rejected second file", func(t *testing.T) {
+		store := storage.NewMemory("/uploads")
+		_, err := UploadFiles(ctx, store, fileHeaders(t,
+			testFile{"logo.png", png},
+			testFile{"evil.png", []byte("%PDF-1.4")},
+		), testUploadConfig)
+		if !errors.Is(err, ErrFileRejected) {
+			t.Fatalf("got %v, want ErrFileRejected", err)
+		}
+		if keys := store.Keys(); len(keys) != 0 {
+			t.Fatalf("stored %v, want first file rolled back", keys)
+		}
+	})
+
+	t.Run("storage error on second file", func(t *testing.T) {
+		store := &failingStorage{Memory: storage.NewMemory("/uploads"), failContentType: "text/plain; charset=utf-8"}
+
+		// file yang sudah ada sebelum batch ini tidak ikut dihapus saat rollback
+		before, err := UploadFiles(ctx, store, fileHeaders(t, testFile{"old.png", existing}), testUploadConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = UploadFiles(ctx, store, fileHeaders(t,
+			testFile{"logo.png", png},
+			testFile{"note.txt", []byte("hello")},
+			testFile{"old.png", existing},
+		), testUploadConfig)
+		if err == nil || errors.Is(err, ErrFileRejected) {
+			t.Fatalf("got %v, want storage error", err)
+		}
+
+		keys := store.Keys()
+		sort.Strings(keys)
+		want := []string{before[0].Key, before[0].ThumbnailKey}
+		sort.Strings(want)
+		if strings.Join(keys, ",") != strings.Join(want, ",") {
+			t.Fatalf("stored %v, want only %v", keys, want)
+		}
+	})
+}
+
+func TestDeleteUploadedFile(t *testing.T) {
+	store := storage.NewMemory("https://cdn.example.com/uploads")
+	ctx := context.Background()
+	results, err := UploadFiles(ctx, store, fileHeaders(t, testFile{"note.txt", []byte("hello")}), testUploadConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// URL milik host lain diabaikan
+	if err := DeleteUploadedFile(ctx, store, "https://other.example.com/uploads/"+results[0].Key, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Object(results[0].Key); !ok {
+		t.Fatal("file deleted through foreign url")
+	}
+
+	if err := DeleteUploadedFile(ctx, store, results[0].URL, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Object(results[0].Key); ok {
+		t.Fatal("file not deleted")
+	}
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header yang dikirim di setiap delivery webhook
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxResponseBody membatasi body response penerima yang disimpan di delivery log
+const maxResponseBody = 1024
+
+// Sign menghitung signature "sha256=<hex>" dari HMAC-SHA256(secret, timestamp + "." + body).
+// Timestamp ikut di-sign supaya penerima bisa menolak request lama yang diputar ulang
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify mengecek signature dari penerima webhook, tolerance 0 berarti timestamp tidak dicek
+func Verify(secret string, timestamp int64, body []byte, signature string, tolerance time.Duration) bool {
+	if tolerance > 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return false
+		}
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Message adalah satu delivery yang akan dikirim
+type Message struct {
+	ID     string
+	Event  string
+	URL    string
+	Secret string
+	Body   []byte
+}
+
+// Result adalah hasil satu percobaan kirim. StatusCode 0 jika request tidak sampai ke penerima
+type Result struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Success true jika penerima membalas 2xx
+func (r *Result) Success() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Sender mengirim webhook dengan HTTP POST JSON. Client bisa diganti, mis. untuk httptest
+type Sender struct {
+	Client *http.Client
+}
+
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		Client: &http.Client{
+			Timeout: timeout,
+			// redirect tidak diikuti supaya body dan signature tidak terkirim ke host lain
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send mengirim message, error hanya untuk kegagalan jaringan (status non-2xx tetap di Result)
+func (s *Sender) Send(ctx context.Context, message *Message) (*Result, error) {
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, message.URL, bytes.NewReader(message.Body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "aestech-webhook/1.0")
+	request.Header.Set(HeaderID, message.ID)
+	request.Header.Set(HeaderEvent, message.Event)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(message.Secret, timestamp, message.Body))
+
+	start := time.Now()
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return &Result{Duration: time.Since(start)}, err
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxResponseBody))
+	result := &Result{
+		StatusCode: response.StatusCode,
+		Body:       strings.ToValidUTF8(string(body), ""),
+		Duration:   time.Since(start),
+	}
+	if !result.Success() {
+		return result, fmt.Errorf("webhook receiver responded %d", response.StatusCode)
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec_test"
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"type":"branch.updated"}`)
+	now := time.Now().Unix()
+	signature := Sign(testSecret, now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		signature string
+		tolerance time.Duration
+		want      bool
+	}{
+		{"valid", testSecret, now, body, signature, 5 * time.Minute, true},
+		{"tampered body", testSecret, now, []byte(`{"type":"branch.deleted"}`), signature, 5 * time.Minute, false},
+		{"wrong secret", "whsec_other", now, body, signature, 5 * time.Minute, false},
+		{"signature without prefix", testSecret, now, body, signature[len("sha256="):], 5 * time.Minute, false},
+		// timestamp ikut di-sign, signature lama tidak berlaku untuk timestamp lain
+		{"changed timestamp", testSecret, now + 1, body, signature, 5 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		if got := Verify(tt.secret, tt.timestamp, tt.body, tt.signature, tt.tolerance); got != tt.want {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTimestampSkew(t *testing.T) {
+	body := []byte(`{}`)
+	tolerance := 5 * time.Minute
+
+	tests := []struct {
+		name      string
+		skew      time.Duration
+		tolerance time.Duration
+		want      bool
+	}{
+		{"inside tolerance", -time.Minute, tolerance, true},
+		{"too old", -10 * time.Minute, tolerance, false},
+		{"too far in future", 10 * time.Minute, tolerance, false},
+		{"tolerance disabled", -24 * time.Hour, 0, true},
+	}
+	for _, tt := range tests {
+		timestamp := time.Now().Add(tt.skew).Unix()
+		signature := Sign(testSecret, timestamp, body)
+		if got := Verify(testSecret, timestamp, body, signature, tt.tolerance); got != tt.want {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testMessage(url string) *Message {
+	return &Message{
+		ID:     "delivery-1",
+		Event:  "branch.updated",
+		URL:    url,
+		Secret: testSecret,
+		Body:   []byte(`{"id":"event-1"}`),
+	}
+}
+
+func TestSendSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil || !Verify(testSecret, timestamp, body, r.Header.Get(HeaderSignature), time.Minute) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" ||
+			r.Header.Get(HeaderID) != "delivery-1" || r.Header.Get(HeaderEvent) != "branch.updated" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	result, err := NewSender(5*time.Second).Send(context.Background(), testMessage(server.URL))
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusAccepted || !result.Success() || result.Body != "ok" {
+		t.Fatalf("result %+v, want 202 ok", result)
+	}
+}
+
+func TestSendNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	result, err := NewSender(5*time.Second).Send(context.Background(), testMessage(server.URL))
+	if err == nil {
+		t.Fatal("Send: want error for 500")
+	}
+	if result == nil || result.StatusCode != http.StatusInternalServerError || result.Success() || result.Body != "boom" {
+		t.Fatalf("result %+v, want 500 boom", result)
+	}
+}
+
+func TestSendDoesNotFollowRedirect(t *testing.T) {
+	var hits atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer target.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	result, err := NewSender(5*time.Second).Send(context.Background(), testMessage(server.URL))
+	if err == nil {
+		t.Fatal("Send: want error for redirect")
+	}
+	if result == nil || result.StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("result %+v, want 307", result)
+	}
+	if hits.Load() != 0 {
+		t.Fatalf("redirect target received %d requests, want 0", hits.Load())
+	}
+}
+
+func TestSendNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	result, err := NewSender(time.Second).Send(context.Background(), testMessage(url))
+	if err == nil {
+		t.Fatal("Send: want error for closed server")
+	}
+	if result == nil || result.StatusCode != 0 {
+		t.Fatalf("result %+v, want status 0", result)
+	}
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    `key`         VARCHAR(64)  NOT NULL,
+    request_hash  VARCHAR(64)  NOT NULL,
+    completed     TINYINT(1)   NOT NULL DEFAULT 0,
+    status_code   INT          NOT NULL DEFAULT 0,
+    content_type  VARCHAR(100) NULL,
+    response_body LONGBLOB     NULL,
+    created_at    DATETIME(3)  NOT NULL,
+    expires_at    DATETIME(3)  NOT NULL,
+    PRIMARY KEY (`key`),
+    INDEX idx_idempotency_keys_expires_at (expires_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE branchs ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
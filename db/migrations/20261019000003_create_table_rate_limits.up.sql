@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    `key`      VARCHAR(191) NOT NULL,
+    tokens     DOUBLE       NOT NULL,
+    updated_at DATETIME(6)  NOT NULL,
+    expires_at DATETIME(6)  NOT NULL,
+    PRIMARY KEY (`key`),
+    INDEX idx_rate_limit_buckets_expires_at (expires_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS rate_limit_lockouts (
+    `key`        VARCHAR(191) NOT NULL,
+    failures     INT          NOT NULL DEFAULT 0,
+    window_ends  DATETIME(6)  NOT NULL,
+    locked_until DATETIME(6)  NULL,
+    PRIMARY KEY (`key`),
+    INDEX idx_rate_limit_lockouts_window_ends (window_ends)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
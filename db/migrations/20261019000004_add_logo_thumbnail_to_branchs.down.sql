@@ -0,0 +1 @@
+ALTER TABLE branchs DROP COLUMN logo_thumbnail;
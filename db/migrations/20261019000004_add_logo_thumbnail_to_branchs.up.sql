@@ -0,0 +1 @@
+ALTER TABLE branchs ADD COLUMN logo_thumbnail TEXT NULL AFTER logo;
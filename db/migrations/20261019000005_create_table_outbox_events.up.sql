@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    seq             BIGINT       NOT NULL AUTO_INCREMENT,
+    id              VARCHAR(36)  NOT NULL,
+    event_type      VARCHAR(100) NOT NULL,
+    aggregate_id    VARCHAR(100) NOT NULL,
+    payload         JSON         NOT NULL,
+    occurred_at     DATETIME(6)  NOT NULL,
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(6)  NOT NULL,
+    locked_until    DATETIME(6)  NULL,
+    published_at    DATETIME(6)  NULL,
+    failed_at       DATETIME(6)  NULL,
+    last_error      TEXT         NULL,
+    PRIMARY KEY (seq),
+    UNIQUE INDEX idx_outbox_events_id (id),
+    INDEX idx_outbox_events_aggregate_id (aggregate_id),
+    INDEX idx_outbox_events_pending (published_at, failed_at, next_attempt_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
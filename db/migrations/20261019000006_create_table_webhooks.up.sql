@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id          VARCHAR(36)  NOT NULL,
+    url         TEXT         NOT NULL,
+    secret      VARCHAR(100) NOT NULL,
+    events      VARCHAR(500) NOT NULL,
+    description VARCHAR(255) NULL,
+    is_active   TINYINT(1)   NOT NULL DEFAULT 1,
+    created_at  DATETIME(6)  NOT NULL,
+    updated_at  DATETIME(6)  NOT NULL,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               VARCHAR(36)  NOT NULL,
+    subscription_id  VARCHAR(36)  NOT NULL,
+    event_id         VARCHAR(36)  NOT NULL,
+    event_type       VARCHAR(100) NOT NULL,
+    payload          JSON         NOT NULL,
+    status           VARCHAR(20)  NOT NULL,
+    attempts         INT          NOT NULL DEFAULT 0,
+    next_attempt_at  DATETIME(6)  NOT NULL,
+    locked_until     DATETIME(6)  NULL,
+    last_status_code INT          NULL,
+    last_response    TEXT         NULL,
+    last_error       TEXT         NULL,
+    delivered_at     DATETIME(6)  NULL,
+    created_at       DATETIME(6)  NOT NULL,
+    updated_at       DATETIME(6)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_webhook_deliveries_event (subscription_id, event_id),
+    INDEX idx_webhook_deliveries_pending (status, next_attempt_at),
+    CONSTRAINT fk_webhook_deliveries_subscription FOREIGN KEY (subscription_id)
+        REFERENCES webhook_subscriptions (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE branchs DROP COLUMN stats_measured_at;
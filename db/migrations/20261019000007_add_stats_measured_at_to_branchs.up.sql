@@ -0,0 +1 @@
+ALTER TABLE branchs ADD COLUMN stats_measured_at DATETIME(6) NULL AFTER last_update_dashboard;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS branch_stats_snapshots (
+    id                   BIGINT       NOT NULL AUTO_INCREMENT,
+    branch_id            VARCHAR(10)  NOT NULL,
+    period               DATE         NOT NULL,
+    measured_at          DATETIME(6)  NOT NULL,
+    avg_guest            INT          NOT NULL DEFAULT 0,
+    avg_transaction      INT          NOT NULL DEFAULT 0,
+    guest_comment_rate   INT          NOT NULL DEFAULT 0,
+    guest_total_by_month INT          NOT NULL DEFAULT 0,
+    trx_total_by_month   INT          NOT NULL DEFAULT 0,
+    rate_receptionist    DOUBLE       NOT NULL DEFAULT 0,
+    rate_doctor          DOUBLE       NOT NULL DEFAULT 0,
+    rate_beautician      DOUBLE       NOT NULL DEFAULT 0,
+    created_at           DATETIME(6)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_branch_stats_snapshots_measured (branch_id, measured_at),
+    INDEX idx_branch_stats_snapshots_period (branch_id, period)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
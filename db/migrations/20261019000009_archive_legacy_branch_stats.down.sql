@@ -0,0 +1,8 @@
+-- kembalikan isi lama hanya ke kolom yang belum diisi push baru
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'top_product' SET b.top_product = l.value WHERE b.top_product IS NULL;
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'top_services' SET b.top_services = l.value WHERE b.top_services IS NULL;
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'top_prof_action' SET b.top_prof_action = l.value WHERE b.top_prof_action IS NULL;
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'chart_activity_by_month' SET b.chart_activity_by_month = l.value WHERE b.chart_activity_by_month IS NULL;
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'chart_sales_by_year' SET b.chart_sales_by_year = l.value WHERE b.chart_sales_by_year IS NULL;
+
+DROP TABLE IF EXISTS branch_stats_legacy;
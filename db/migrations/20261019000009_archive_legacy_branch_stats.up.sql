@@ -0,0 +1,51 @@
+-- Kolom statistik dashboard sekarang dibaca sebagai JSON bertipe (entity.TopList dan entity.Chart)
+-- dan isi yang tidak sesuai format membuat query cabang gagal. Isi lama yang tidak sesuai
+-- dipindah ke branch_stats_legacy lalu dikosongkan, push statistik berikutnya mengisi ulang
+CREATE TABLE IF NOT EXISTS branch_stats_legacy (
+    branch_id   VARCHAR(10)  NOT NULL,
+    column_name VARCHAR(64)  NOT NULL,
+    value       LONGTEXT     NOT NULL,
+    archived_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (branch_id, column_name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+SET @top_list_schema = '{"type":"array","items":{"type":"object","additionalProperties":false,"properties":{"name":{"type":"string"},"total":{"type":"number"}}}}';
+SET @chart_schema = '{"type":"object","additionalProperties":false,"properties":{"labels":{"type":["array","null"],"items":{"type":"string"}},"series":{"type":["array","null"],"items":{"type":"object","additionalProperties":false,"properties":{"name":{"type":"string"},"data":{"type":["array","null"],"items":{"type":"number"}}}}}}}';
+
+-- string kosong dan "null" sudah berarti tidak ada data
+UPDATE branchs SET top_product = NULL WHERE TRIM(top_product) IN ('', 'null');
+UPDATE branchs SET top_services = NULL WHERE TRIM(top_services) IN ('', 'null');
+UPDATE branchs SET top_prof_action = NULL WHERE TRIM(top_prof_action) IN ('', 'null');
+UPDATE branchs SET chart_activity_by_month = NULL WHERE TRIM(chart_activity_by_month) IN ('', 'null');
+UPDATE branchs SET chart_sales_by_year = NULL WHERE TRIM(chart_sales_by_year) IN ('', 'null');
+
+INSERT INTO branch_stats_legacy (branch_id, column_name, value)
+SELECT id, 'top_product', top_product FROM branchs
+WHERE top_product IS NOT NULL
+  AND (CASE WHEN JSON_VALID(top_product) THEN JSON_SCHEMA_VALID(@top_list_schema, top_product) ELSE 0 END) = 0;
+
+INSERT INTO branch_stats_legacy (branch_id, column_name, value)
+SELECT id, 'top_services', top_services FROM branchs
+WHERE top_services IS NOT NULL
+  AND (CASE WHEN JSON_VALID(top_services) THEN JSON_SCHEMA_VALID(@top_list_schema, top_services) ELSE 0 END) = 0;
+
+INSERT INTO branch_stats_legacy (branch_id, column_name, value)
+SELECT id, 'top_prof_action', top_prof_action FROM branchs
+WHERE top_prof_action IS NOT NULL
+  AND (CASE WHEN JSON_VALID(top_prof_action) THEN JSON_SCHEMA_VALID(@top_list_schema, top_prof_action) ELSE 0 END) = 0;
+
+INSERT INTO branch_stats_legacy (branch_id, column_name, value)
+SELECT id, 'chart_activity_by_month', chart_activity_by_month FROM branchs
+WHERE chart_activity_by_month IS NOT NULL
+  AND (CASE WHEN JSON_VALID(chart_activity_by_month) THEN JSON_SCHEMA_VALID(@chart_schema, chart_activity_by_month) ELSE 0 END) = 0;
+
+INSERT INTO branch_stats_legacy (branch_id, column_name, value)
+SELECT id, 'chart_sales_by_year', chart_sales_by_year FROM branchs
+WHERE chart_sales_by_year IS NOT NULL
+  AND (CASE WHEN JSON_VALID(chart_sales_by_year) THEN JSON_SCHEMA_VALID(@chart_schema, chart_sales_by_year) ELSE 0 END) = 0;
+
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'top_product' SET b.top_product = NULL;
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'top_services' SET b.top_services = NULL;
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'top_prof_action' SET b.top_prof_action = NULL;
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'chart_activity_by_month' SET b.chart_activity_by_month = NULL;
+UPDATE branchs b JOIN branch_stats_legacy l ON l.branch_id = b.id AND l.column_name = 'chart_sales_by_year' SET b.chart_sales_by_year = NULL;
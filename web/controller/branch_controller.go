@@ -4,6 +4,7 @@ import (
 	"backend/core/utils"
 	"backend/web/model"
 	"backend/web/service"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
@@ -25,13 +26,13 @@ func (c *BranchsController) AddNewManagement(ctx *fiber.Ctx) error {
 	request := new(model.CreateManagementRequest)
 	err := ctx.BodyParser(request)
 	if err != nil {
-		c.Log.Warnf("Failed to parse request body : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
 		return fiber.ErrBadRequest
 	}
 
 	res, err := c.Service.AddNewManagement(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.Warnf("Failed to create management : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to create management : %+v", err)
 		return err
 	}
 
@@ -42,15 +43,173 @@ func (c *BranchsController) AddNewBranch(ctx *fiber.Ctx) error {
 	request := new(model.CreateBranchRequest)
 	err := ctx.BodyParser(request)
 	if err != nil {
-		c.Log.Warnf("Failed to parse request body : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
 		return fiber.ErrBadRequest
 	}
 
 	res, err := c.Service.AddNewBranch(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.Warnf("Failed to create management : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to create management : %+v", err)
 		return err
 	}
 
 	return ctx.JSON(utils.WebResponse[*model.CreateBranchResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
 }
+
+func (c *BranchsController) ListBranches(ctx *fiber.Ctx) error {
+	page, size := pageQuery(ctx)
+
+	// pakai pagination cursor jika query cursor dikirim (kosong untuk halaman pertama)
+	if _, ok := ctx.Queries()["cursor"]; ok {
+		res, cursor, err := c.Service.ListBranchesCursor(ctx.UserContext(), ctx.Queries(), ctx.Query("cursor"), size)
+		if err != nil {
+			c.Log.WithContext(ctx.UserContext()).Warnf("Failed to list branches : %+v", err)
+			return err
+		}
+
+		return ctx.JSON(utils.WebResponse[[]*model.BranchResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res, Cursor: cursor})
+	}
+
+	res, paging, err := c.Service.ListBranches(ctx.UserContext(), ctx.Queries(), page, size)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to list branches : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[[]*model.BranchResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res, Paging: paging})
+}
+
+func (c *BranchsController) GetBranch(ctx *fiber.Ctx) error {
+	res, err := c.Service.GetBranch(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to get branch : %+v", err)
+		return err
+	}
+
+	ctx.Set(fiber.HeaderETag, utils.VersionETag(res.Version))
+	if ctx.Fresh() {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	return ctx.JSON(utils.WebResponse[*model.BranchResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *BranchsController) UpdateBranch(ctx *fiber.Ctx) error {
+	// If-Match wajib supaya perubahan operator lain tidak tertimpa diam-diam
+	ifMatch := ctx.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return utils.NewAppError(fiber.StatusPreconditionRequired, utils.ErrCodePreconditionRequired, "")
+	}
+	version, ok := utils.ParseVersionETag(ifMatch)
+	if !ok {
+		return utils.NewAppError(fiber.StatusPreconditionFailed, utils.ErrCodePreconditionFailed, "")
+	}
+
+	request := new(model.UpdateBranchRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	res, err := c.Service.UpdateBranch(ctx.UserContext(), ctx.Params("id"), version, request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to update branch : %+v", err)
+		return err
+	}
+
+	ctx.Set(fiber.HeaderETag, utils.VersionETag(res.Version))
+	return ctx.JSON(utils.WebResponse[*model.BranchResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *BranchsController) UpdateLogo(ctx *fiber.Ctx) error {
+	file, err := ctx.FormFile("logo")
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to read logo file : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	res, err := c.Service.UpdateLogo(ctx.UserContext(), ctx.Params("id"), file, ctx.BaseURL())
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to update logo : %+v", err)
+		return err
+	}
+
+	ctx.Set(fiber.HeaderETag, utils.VersionETag(res.Version))
+	return ctx.JSON(utils.WebResponse[*model.BranchResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *BranchsController) UpdateStats(ctx *fiber.Ctx) error {
+	request := new(model.BranchStatsRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	res, err := c.Service.UpdateStats(ctx.UserContext(), ctx.Params("id"), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to update branch stats : %+v", err)
+		return err
+	}
+
+	ctx.Set(fiber.HeaderETag, utils.VersionETag(res.Version))
+	return ctx.JSON(utils.WebResponse[*model.BranchResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *BranchsController) UpdateStatsBatch(ctx *fiber.Ctx) error {
+	request := new(model.BranchStatsBatchRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	res, err := c.Service.UpdateStatsBatch(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to update branch stats : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[[]*model.BranchStatsBatchResult]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *BranchsController) StatsHistory(ctx *fiber.Ctx) error {
+	res, err := c.Service.StatsHistory(ctx.UserContext(), ctx.Params("id"), ctx.Query("range"), ctx.Query("interval"))
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to get branch stats history : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[*model.BranchStatsHistoryResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *BranchsController) CompareStats(ctx *fiber.Ctx) error {
+	ids := make([]string, 0)
+	for _, id := range strings.Split(ctx.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	res, err := c.Service.CompareStats(ctx.UserContext(), ids, ctx.Query("range"), ctx.Query("interval"))
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to compare branch stats : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[[]*model.BranchStatsHistoryResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+// pageQuery membaca query page dan size list endpoint, size maksimal 100
+func pageQuery(ctx *fiber.Ctx) (int, int) {
+	page := ctx.QueryInt("page", 1)
+	size := ctx.QueryInt("size", 10)
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 10
+	}
+	return page, size
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"backend/core/utils"
+	"backend/web/model"
+	"backend/web/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookController struct {
+	Log     *logrus.Logger
+	Service *service.WebhookService
+}
+
+func NewWebhookController(service *service.WebhookService, logger *logrus.Logger) *WebhookController {
+	return &WebhookController{
+		Log:     logger,
+		Service: service,
+	}
+}
+
+func (c *WebhookController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateWebhookRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	res, err := c.Service.CreateSubscription(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to create webhook : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[*model.WebhookResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *WebhookController) List(ctx *fiber.Ctx) error {
+	page, size := pageQuery(ctx)
+
+	res, paging, err := c.Service.ListSubscriptions(ctx.UserContext(), page, size)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to list webhooks : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[[]*model.WebhookResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res, Paging: paging})
+}
+
+func (c *WebhookController) Get(ctx *fiber.Ctx) error {
+	res, err := c.Service.GetSubscription(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to get webhook : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[*model.WebhookResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *WebhookController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateWebhookRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	res, err := c.Service.UpdateSubscription(ctx.UserContext(), ctx.Params("id"), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to update webhook : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[*model.WebhookResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
+
+func (c *WebhookController) Delete(ctx *fiber.Ctx) error {
+	if err := c.Service.DeleteSubscription(ctx.UserContext(), ctx.Params("id")); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to delete webhook : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[bool]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: true})
+}
+
+func (c *WebhookController) ListDeliveries(ctx *fiber.Ctx) error {
+	page, size := pageQuery(ctx)
+
+	res, paging, err := c.Service.ListDeliveries(ctx.UserContext(), ctx.Params("id"), ctx.Query("status"), page, size)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to list webhook deliveries : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[[]*model.WebhookDeliveryResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res, Paging: paging})
+}
+
+func (c *WebhookController) Redeliver(ctx *fiber.Ctx) error {
+	res, err := c.Service.Redeliver(ctx.UserContext(), ctx.Params("id"), ctx.Params("deliveryId"))
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to redeliver webhook : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(utils.WebResponse[*model.WebhookDeliveryResponse]{Status: true, Message: "Success", Code: fiber.StatusOK, Data: res})
+}
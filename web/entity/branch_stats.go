@@ -0,0 +1,163 @@
+package entity
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// batas ukuran payload statistik supaya kolom text tidak menampung data sembarang besar
+const (
+	MaxTopItems     = 50
+	MaxChartLabels  = 366
+	MaxChartSeries  = 10
+	maxStatsNameLen = 255
+)
+
+// TopItem adalah satu baris top-N dashboard (produk, layanan atau tindakan)
+type TopItem struct {
+	Name  string  `json:"name"`
+	Total float64 `json:"total"`
+}
+
+// TopList disimpan sebagai JSON array di kolom text top_product, top_services dan
+// top_prof_action. Nil disimpan sebagai NULL
+type TopList []TopItem
+
+// ChartSeries adalah satu garis/batang chart, Data sejajar dengan Chart.Labels
+type ChartSeries struct {
+	Name string    `json:"name"`
+	Data []float64 `json:"data"`
+}
+
+// Chart disimpan sebagai JSON {labels, series} di kolom chart_activity_by_month dan
+// chart_sales_by_year, format yang langsung dipakai chart dashboard
+type Chart struct {
+	Labels []string      `json:"labels"`
+	Series []ChartSeries `json:"series"`
+}
+
+func (t TopList) Validate() error {
+	if len(t) > MaxTopItems {
+		return fmt.Errorf("top list maksimal %d item", MaxTopItems)
+	}
+	for i, item := range t {
+		if item.Name == "" || len(item.Name) > maxStatsNameLen {
+			return fmt.Errorf("top list item %d: name wajib diisi, maksimal %d karakter", i, maxStatsNameLen)
+		}
+		if !validNumber(item.Total) || item.Total < 0 {
+			return fmt.Errorf("top list item %d: total harus angka tidak negatif", i)
+		}
+	}
+	return nil
+}
+
+func (c Chart) Validate() error {
+	if len(c.Labels) > MaxChartLabels {
+		return fmt.Errorf("chart maksimal %d label", MaxChartLabels)
+	}
+	if len(c.Series) > MaxChartSeries {
+		return fmt.Errorf("chart maksimal %d series", MaxChartSeries)
+	}
+	for i, series := range c.Series {
+		if series.Name == "" || len(series.Name) > maxStatsNameLen {
+			return fmt.Errorf("chart series %d: name wajib diisi, maksimal %d karakter", i, maxStatsNameLen)
+		}
+		if len(series.Data) != len(c.Labels) {
+			return fmt.Errorf("chart series %d: jumlah data harus sama dengan jumlah label (%d)", i, len(c.Labels))
+		}
+		for _, value := range series.Data {
+			if !validNumber(value) {
+				return fmt.Errorf("chart series %d: data harus angka", i)
+			}
+		}
+	}
+	return nil
+}
+
+// Value memvalidasi payload sebelum ditulis, jadi data rusak tidak pernah masuk ke
+// database walau lewat jalur selain API
+func (t TopList) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return marshalStats(t)
+}
+
+// Scan membaca kolom text. Isi yang bukan JSON array TopItem dikembalikan sebagai error,
+// bukan dianggap kosong, supaya data lama tidak hilang diam-diam lalu tertimpa NULL saat
+// cabang disimpan. Data lama dipindah ke branch_stats_legacy oleh migrasi 20261019000009
+func (t *TopList) Scan(src any) error {
+	var list TopList
+	if err := unmarshalStats(src, &list); err != nil {
+		return fmt.Errorf("top list bukan JSON array {name, total}: %w", err)
+	}
+	*t = list
+	return nil
+}
+
+func (c Chart) Value() (driver.Value, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return marshalStats(c)
+}
+
+// Scan membaca kolom longtext, isi yang bukan JSON {labels, series} dikembalikan sebagai error
+func (c *Chart) Scan(src any) error {
+	var chart Chart
+	if err := unmarshalStats(src, &chart); err != nil {
+		return fmt.Errorf("chart bukan JSON {labels, series}: %w", err)
+	}
+	*c = chart
+	return nil
+}
+
+func marshalStats(value any) (driver.Value, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+// unmarshalStats membaca JSON kolom statistik ke dest. NULL, string kosong dan "null"
+// dibiarkan kosong. Field yang tidak dikenal ditolak supaya format lain tidak terbaca sebagai data kosong
+func unmarshalStats(src any, dest any) error {
+	var raw []byte
+	switch value := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		raw = value
+	case string:
+		raw = []byte(value)
+	default:
+		return fmt.Errorf("tipe kolom %T tidak didukung", src)
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("ada data setelah JSON")
+	}
+	return nil
+}
+
+func validNumber(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// BranchStatsSnapshot adalah tabel branch_stats_snapshots, satu baris per push statistik
+// yang diterima. Period adalah tanggal measured_at, dipakai untuk agregasi harian/mingguan/bulanan.
+// Hanya statistik angka yang disimpan, top-N dan chart cukup versi terakhir di tabel branchs
+type BranchStatsSnapshot struct {
+	ID                int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	BranchID          string    `gorm:"column:branch_id;size:10;not null;uniqueIndex:idx_branch_stats_snapshots_measured,priority:1;index:idx_branch_stats_snapshots_period,priority:1"`
+	Period            time.Time `gorm:"column:period;type:date;not null;index:idx_branch_stats_snapshots_period,priority:2"`
+	MeasuredAt        time.Time `gorm:"column:measured_at;type:datetime(6);not null;uniqueIndex:idx_branch_stats_snapshots_measured,priority:2"`
+	AvgGuest          int       `gorm:"column:avg_guest;not null;default:0"`
+	AvgTransaction    int       `gorm:"column:avg_transaction;not null;default:0"`
+	GuestCommentRate  int       `gorm:"column:guest_comment_rate;not null;default:0"`
+	GuestTotalByMonth int       `gorm:"column:guest_total_by_month;not null;default:0"`
+	TrxTotalByMonth   int       `gorm:"column:trx_total_by_month;not null;default:0"`
+	RateReceptionist  float64   `gorm:"column:rate_receptionist;not null;default:0"`
+	RateDoctor        float64   `gorm:"column:rate_doctor;not null;default:0"`
+	RateBeautician    float64   `gorm:"column:rate_beautician;not null;default:0"`
+	CreatedAt         time.Time `gorm:"column:created_at;type:datetime(6);not null"`
+}
+
+func (s *BranchStatsSnapshot) TableName() string {
+	return "branch_stats_snapshots"
+}
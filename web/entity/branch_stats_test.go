@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTopListScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    int
+		wantErr bool
+	}{
+		{"null column", nil, 0, false},
+		{"empty string", "", 0, false},
+		{"json null", []byte(" null "), 0, false},
+		{"valid", []byte(`[{"name":"Facial","total":12},{"name":"Peeling","total":3.5}]`), 2, false},
+		{"valid string", `[{"name":"Facial","total":12}]`, 1, false},
+		{"not json", "Facial:12,Peeling:3", 0, true},
+		{"object instead of array", `{"name":"Facial","total":12}`, 0, true},
+		// format lain tidak boleh terbaca sebagai item kosong
+		{"unknown fields", `[{"nama":"Facial","jumlah":12}]`, 0, true},
+		{"trailing data", `[] []`, 0, true},
+		{"unsupported type", int64(1), 0, true},
+	}
+	for _, tt := range tests {
+		list := TopList{{Name: "old"}}
+		err := list.Scan(tt.src)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: Scan = %v, want error", tt.name, list)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Scan error %v", tt.name, err)
+			continue
+		}
+		if len(list) != tt.want {
+			t.Errorf("%s: got %d items, want %d", tt.name, len(list), tt.want)
+		}
+	}
+}
+
+func TestChartScan(t *testing.T) {
+	var chart Chart
+	if err := chart.Scan(`{"labels":["Jan","Feb"],"series":[{"name":"Tamu","data":[1,2]}]}`); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(chart.Labels) != 2 || len(chart.Series) != 1 || chart.Series[0].Data[1] != 2 {
+		t.Fatalf("chart %+v", chart)
+	}
+
+	for _, src := range []any{`[1,2,3]`, `{"labels":["Jan"],"values":[1]}`, `{"labels":`} {
+		if err := new(Chart).Scan(src); err == nil {
+			t.Errorf("Scan(%v): want error", src)
+		}
+	}
+}
+
+func TestStatsValue(t *testing.T) {
+	value, err := TopList(nil).Value()
+	if err != nil || value != nil {
+		t.Fatalf("nil TopList Value = %v, %v; want NULL", value, err)
+	}
+
+	value, err = TopList{{Name: "Facial", Total: 1}}.Value()
+	if err != nil || value != `[{"name":"Facial","total":1}]` {
+		t.Fatalf("TopList Value = %v, %v", value, err)
+	}
+
+	if _, err := (TopList{{Name: "", Total: 1}}).Value(); err == nil {
+		t.Error("TopList without name: want error")
+	}
+	if _, err := (TopList{{Name: "Facial", Total: -1}}).Value(); err == nil {
+		t.Error("TopList negative total: want error")
+	}
+	if _, err := (Chart{Labels: []string{"Jan"}, Series: []ChartSeries{{Name: "Tamu", Data: []float64{1, 2}}}}).Value(); err == nil {
+		t.Error("Chart mismatched data length: want error")
+	}
+	if _, err := (Chart{Labels: []string{"Jan"}, Series: []ChartSeries{{Name: "Tamu", Data: []float64{math.NaN()}}}}).Value(); err == nil {
+		t.Error("Chart NaN: want error")
+	}
+}
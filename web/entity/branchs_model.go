@@ -2,9 +2,12 @@ package entity
 
 import "time"
 
+// Branch adalah tabel branchs. Kolom dengan tag filter:"-" tidak bisa dipakai
+// di filter/sort query string list endpoint
 type Branch struct {
 	ID                   string     `gorm:"column:id;primaryKey;size:10"`
 	Logo                 *string    `gorm:"column:logo;type:text"`
+	LogoThumbnail        *string    `gorm:"column:logo_thumbnail;type:text"`
 	NamaCabang           string     `gorm:"column:nama_cabang;size:150;not null"`
 	Alamat               string     `gorm:"column:alamat;size:255;not null"`
 	Kota                 string     `gorm:"column:kota;size:100;not null"`
@@ -14,9 +17,9 @@ type Branch struct {
 	Sipa                 string     `gorm:"column:sipa;size:255;not null"`
 	IsPrivate            bool       `gorm:"column:is_private;not null;default:0"`
 	Pettycash            int        `gorm:"column:pettycash;not null;default:0"`
-	IP                   *string    `gorm:"column:ip;size:50"`
-	KeyMachine           *string    `gorm:"column:key_machine;size:10"`
-	IPMachine            *string    `gorm:"column:ip_machine;size:50"`
+	IP                   *string    `gorm:"column:ip;size:50" filter:"-"`
+	KeyMachine           *string    `gorm:"column:key_machine;size:10" filter:"-"`
+	IPMachine            *string    `gorm:"column:ip_machine;size:50" filter:"-"`
 	BpomMode             bool       `gorm:"column:bpom_mode;default:0"`
 	PPN                  int16      `gorm:"column:ppn;default:0"`
 	Datetime             *string    `gorm:"column:datetime;size:200;default:'Asia/Jakarta'"`
@@ -32,22 +35,26 @@ type Branch struct {
 	AvgGuest             *int       `gorm:"column:avg_guest;default:0"`
 	AvgTransaction       *int       `gorm:"column:avg_transaction;default:0"`
 	GuestCommentRate     *int       `gorm:"column:guest_comment_rate;default:0"`
-	TopProduct           *string    `gorm:"column:top_product;type:text"`
-	TopServices          *string    `gorm:"column:top_services;type:text"`
-	TopProfAction        *string    `gorm:"column:top_prof_action;type:text"`
+	TopProduct           TopList    `gorm:"column:top_product;type:text" filter:"-"`
+	TopServices          TopList    `gorm:"column:top_services;type:text" filter:"-"`
+	TopProfAction        TopList    `gorm:"column:top_prof_action;type:text" filter:"-"`
 	GuestTotalByMonth    *int       `gorm:"column:guest_total_by_month;default:0"`
 	TrxTotalByMonth      *int       `gorm:"column:trx_total_by_month;default:0"`
-	ChartActivityByMonth *string    `gorm:"column:chart_activity_by_month;type:longtext"`
-	ChartSalesByYear     *string    `gorm:"column:chart_sales_by_year;type:longtext"`
+	ChartActivityByMonth *Chart     `gorm:"column:chart_activity_by_month;type:longtext" filter:"-"`
+	ChartSalesByYear     *Chart     `gorm:"column:chart_sales_by_year;type:longtext" filter:"-"`
 	RateReceptionist     *float64   `gorm:"column:rate_receptionist;default:0"`
 	RateDoctor           *float64   `gorm:"column:rate_doctor;default:0"`
 	RateBeautician       *float64   `gorm:"column:rate_beautician;default:0"`
-	IDKlien              string     `gorm:"column:id_klien;size:255;not null"`
+	IDKlien              string     `gorm:"column:id_klien;size:255;not null" filter:"-"`
 	NoWhatsapp           *string    `gorm:"column:no_whatsapp;size:50"`
-	XenditID             *string    `gorm:"column:xendit_id;size:100"`
-	WalletID             *string    `gorm:"column:wallet_id;size:100"`
-	AccessID             *string    `gorm:"column:access_id;size:255"`
+	XenditID             *string    `gorm:"column:xendit_id;size:100" filter:"-"`
+	WalletID             *string    `gorm:"column:wallet_id;size:100" filter:"-"`
+	AccessID             *string    `gorm:"column:access_id;size:255" filter:"-"`
 	AccessStatus         *bool      `gorm:"column:access_status;default:0"`
+	// StatsMeasuredAt adalah waktu statistik push terakhir dihitung, push yang lebih lama ditolak
+	StatsMeasuredAt *time.Time `gorm:"column:stats_measured_at;type:datetime(6)"`
+	// Version naik setiap update, dipakai sebagai ETag untuk mencegah lost update
+	Version int64 `gorm:"column:version;not null;default:1"`
 }
 
 func (u *Branch) TableName() string {
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status delivery webhook
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookDead      = "dead"
+)
+
+// WebhookSubscription adalah tabel webhook_subscriptions. Events berisi tipe event
+// dipisah koma, atau "*" untuk semua event
+type WebhookSubscription struct {
+	ID          string    `gorm:"column:id;primaryKey;size:36"`
+	URL         string    `gorm:"column:url;type:text;not null"`
+	Secret      string    `gorm:"column:secret;size:100;not null" filter:"-"`
+	Events      string    `gorm:"column:events;size:500;not null"`
+	Description *string   `gorm:"column:description;size:255"`
+	IsActive    bool      `gorm:"column:is_active;not null;default:1"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:datetime(6);not null"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:datetime(6);not null"`
+}
+
+func (w *WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookDelivery adalah tabel webhook_deliveries, satu baris per event per subscription
+// beserta hasil percobaan terakhir. Payload adalah body yang dikirim, dipakai ulang saat redeliver
+type WebhookDelivery struct {
+	ID             string          `gorm:"column:id;primaryKey;size:36"`
+	SubscriptionID string          `gorm:"column:subscription_id;size:36;not null;uniqueIndex:idx_webhook_deliveries_event,priority:1"`
+	EventID        string          `gorm:"column:event_id;size:36;not null;uniqueIndex:idx_webhook_deliveries_event,priority:2"`
+	EventType      string          `gorm:"column:event_type;size:100;not null"`
+	Payload        json.RawMessage `gorm:"column:payload;type:json;not null"`
+	Status         string          `gorm:"column:status;size:20;not null"`
+	Attempts       int             `gorm:"column:attempts;not null;default:0"`
+	NextAttemptAt  time.Time       `gorm:"column:next_attempt_at;type:datetime(6);not null"`
+	LockedUntil    *time.Time      `gorm:"column:locked_until;type:datetime(6)"`
+	LastStatusCode *int            `gorm:"column:last_status_code"`
+	LastResponse   *string         `gorm:"column:last_response;type:text"`
+	LastError      *string         `gorm:"column:last_error;type:text"`
+	DeliveredAt    *time.Time      `gorm:"column:delivered_at;type:datetime(6)"`
+	CreatedAt      time.Time       `gorm:"column:created_at;type:datetime(6);not null"`
+	UpdatedAt      time.Time       `gorm:"column:updated_at;type:datetime(6);not null"`
+}
+
+func (w *WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Tipe domain event cabang yang ditulis ke outbox
+const (
+	EventBranchCreated         = "branch.created"
+	EventBranchUpdated         = "branch.updated"
+	EventBranchExpired         = "branch.expired"
+	EventBranchMovedManagement = "branch.moved_management"
+	EventBranchStatsUpdated    = "branch.stats_updated"
+)
+
+// BranchEventTypes adalah semua tipe event cabang, dipakai untuk validasi filter event
+var BranchEventTypes = []string{EventBranchCreated, EventBranchUpdated, EventBranchExpired, EventBranchMovedManagement, EventBranchStatsUpdated}
+
+// BranchCreatedEvent dikirim saat cabang atau manajemen baru dibuat
+type BranchCreatedEvent struct {
+	Branch *BranchResponse `json:"branch"`
+}
+
+// BranchUpdatedEvent berisi data cabang setelah diubah dan nama field yang berubah
+type BranchUpdatedEvent struct {
+	Branch  *BranchResponse `json:"branch"`
+	Changes []string        `json:"changes"`
+}
+
+// BranchExpiredEvent dikirim sekali per tanggal expire untuk cabang trial yang sudah lewat masa aktif
+type BranchExpiredEvent struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Upline     string    `json:"upline"`
+	ExpireDate time.Time `json:"expireDate"`
+}
+
+// BranchMovedManagementEvent dikirim saat cabang dipindah ke manajemen (upline) lain
+type BranchMovedManagementEvent struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// BranchStatsUpdatedEvent dikirim setiap push statistik dashboard yang diterima
+type BranchStatsUpdatedEvent struct {
+	ID    string              `json:"id"`
+	Stats BranchStatsResponse `json:"stats"`
+}
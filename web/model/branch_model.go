@@ -1,10 +1,12 @@
 package model
 
+import "time"
+
 type CreateManagementRequest struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Address string `json:"address"`
-	Email   string `json:"email"`
+	ID      string `json:"id" validate:"required,branch_id"`
+	Name    string `json:"name" validate:"required,max=150"`
+	Address string `json:"address" validate:"required,max=255"`
+	Email   string `json:"email" validate:"required,email,max=150"`
 }
 
 type CreateManagementResponse struct {
@@ -14,11 +16,18 @@ type CreateManagementResponse struct {
 }
 
 type CreateBranchRequest struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Address string `json:"address"`
-	Email   string `json:"email"`
-	Upline  string `json:"upline"`
+	ID         string `json:"id" validate:"required,branch_id"`
+	Name       string `json:"name" validate:"required,max=150"`
+	Address    string `json:"address" validate:"required,max=255"`
+	Email      string `json:"email" validate:"required,email,max=150"`
+	Upline     string `json:"upline" validate:"required,branch_id"`
+	City       string `json:"city" validate:"omitempty,max=100"`
+	Contact    string `json:"contact" validate:"omitempty,phone_id,max=25"`
+	WhatsApp   string `json:"whatsapp" validate:"omitempty,whatsapp_id,max=50"`
+	Coordinate string `json:"coordinate" validate:"omitempty,latlng,max=255"`
+	Sipa       string `json:"sipa" validate:"omitempty,sipa,max=255"`
+	Timezone   string `json:"timezone" validate:"omitempty,iana_timezone,max=200"`
+	RoundPPN   string `json:"roundPpn" validate:"omitempty,roundppn"`
 }
 
 type CreateBranchResponse struct {
@@ -26,3 +35,185 @@ type CreateBranchResponse struct {
 	Name    string `json:"name"`
 	Address string `json:"address"`
 }
+
+type BranchStateCount struct {
+	Trial       int64 `json:"trial"`
+	Paid        int64 `json:"paid"`
+	Expired     int64 `json:"expired"`
+	Deleted     int64 `json:"deleted"`
+	Managements int64 `json:"managements"`
+}
+
+// ProvisioningDefaults adalah nilai default saat membuat cabang atau manajemen baru,
+// dibaca dari section "provisioning" di config dan bisa diubah tanpa restart
+type ProvisioningDefaults struct {
+	Kota       string `mapstructure:"kota" validate:"required"`
+	Kontak     string `mapstructure:"kontak" validate:"required"`
+	Timezone   string `mapstructure:"timezone" validate:"required,iana_timezone"`
+	RoundPPN   string `mapstructure:"roundPpn" validate:"required,roundppn"`
+	PPN        int16  `mapstructure:"ppn" validate:"min=0,max=100"`
+	BpomMode   bool   `mapstructure:"bpomMode"`
+	ExpireDate string `mapstructure:"expireDate" validate:"required,datetime=2006-01-02"`
+}
+
+type BranchResponse struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Address       string     `json:"address"`
+	City          string     `json:"city"`
+	Contact       string     `json:"contact"`
+	Email         string     `json:"email"`
+	WhatsApp      *string    `json:"whatsapp"`
+	Coordinate    string     `json:"coordinate"`
+	Sipa          string     `json:"sipa"`
+	Timezone      *string    `json:"timezone"`
+	RoundPPN      *string    `json:"roundPpn"`
+	PPN           int16      `json:"ppn"`
+	BpomMode      bool       `json:"bpomMode"`
+	Upline        string     `json:"upline"`
+	IsManagement  bool       `json:"isManagement"`
+	IsPaid        bool       `json:"isPaid"`
+	ExpireDate    *time.Time `json:"expireDate"`
+	Logo          *string    `json:"logo"`
+	LogoThumbnail *string    `json:"logoThumbnail"`
+	Version       int64      `json:"version"`
+	// Stats adalah statistik dashboard cabang
+	Stats BranchStatsResponse `json:"stats"`
+}
+
+// TopItem adalah satu baris top-N dashboard (produk, layanan atau tindakan)
+type TopItem struct {
+	Name  string  `json:"name" validate:"required,max=255"`
+	Total float64 `json:"total" validate:"min=0"`
+}
+
+// ChartSeries adalah satu garis/batang chart, data sejajar dengan labels
+type ChartSeries struct {
+	Name string    `json:"name" validate:"required,max=255"`
+	Data []float64 `json:"data"`
+}
+
+// Chart divalidasi juga di level struct: jumlah data setiap series harus sama dengan labels
+type Chart struct {
+	Labels []string      `json:"labels" validate:"max=366,dive,max=50"`
+	Series []ChartSeries `json:"series" validate:"max=10,dive"`
+}
+
+// BranchStatsResponse berisi statistik dashboard, chart dan top-N dalam bentuk JSON
+// (null jika belum pernah diisi)
+type BranchStatsResponse struct {
+	AvgGuest             int        `json:"avgGuest"`
+	AvgTransaction       int        `json:"avgTransaction"`
+	GuestCommentRate     int        `json:"guestCommentRate"`
+	GuestTotalByMonth    int        `json:"guestTotalByMonth"`
+	TrxTotalByMonth      int        `json:"trxTotalByMonth"`
+	RateReceptionist     float64    `json:"rateReceptionist"`
+	RateDoctor           float64    `json:"rateDoctor"`
+	RateBeautician       float64    `json:"rateBeautician"`
+	TopProduct           []TopItem  `json:"topProduct"`
+	TopServices          []TopItem  `json:"topServices"`
+	TopProfAction        []TopItem  `json:"topProfAction"`
+	ChartActivityByMonth *Chart     `json:"chartActivityByMonth"`
+	ChartSalesByYear     *Chart     `json:"chartSalesByYear"`
+	LastUpdate           *time.Time `json:"lastUpdate"`
+	MeasuredAt           *time.Time `json:"measuredAt"`
+}
+
+// BranchStatsRequest adalah statistik dashboard agregat yang dikirim sistem POS atau job
+// sinkronisasi. Semua statistik diganti, top-N dan chart yang tidak dikirim menjadi kosong
+type BranchStatsRequest struct {
+	// MeasuredAt adalah waktu statistik dihitung, harus lebih baru dari push sebelumnya
+	MeasuredAt           time.Time `json:"measuredAt" validate:"required"`
+	AvgGuest             int       `json:"avgGuest" validate:"min=0"`
+	AvgTransaction       int       `json:"avgTransaction" validate:"min=0"`
+	GuestCommentRate     int       `json:"guestCommentRate" validate:"min=0,max=100"`
+	GuestTotalByMonth    int       `json:"guestTotalByMonth" validate:"min=0"`
+	TrxTotalByMonth      int       `json:"trxTotalByMonth" validate:"min=0"`
+	RateReceptionist     float64   `json:"rateReceptionist" validate:"min=0,max=100"`
+	RateDoctor           float64   `json:"rateDoctor" validate:"min=0,max=100"`
+	RateBeautician       float64   `json:"rateBeautician" validate:"min=0,max=100"`
+	TopProduct           []TopItem `json:"topProduct" validate:"omitempty,max=50,dive"`
+	TopServices          []TopItem `json:"topServices" validate:"omitempty,max=50,dive"`
+	TopProfAction        []TopItem `json:"topProfAction" validate:"omitempty,max=50,dive"`
+	ChartActivityByMonth *Chart    `json:"chartActivityByMonth"`
+	ChartSalesByYear     *Chart    `json:"chartSalesByYear"`
+}
+
+type BranchStatsBatchItem struct {
+	ID    string             `json:"id" validate:"required,branch_id"`
+	Stats BranchStatsRequest `json:"stats"`
+}
+
+// BranchStatsBatchRequest adalah body PUT /branch/stats, setiap cabang diproses terpisah
+type BranchStatsBatchRequest struct {
+	Items []BranchStatsBatchItem `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+// Status hasil per cabang di BranchStatsBatchResult
+const (
+	StatsUpdated  = "updated"
+	StatsStale    = "stale"
+	StatsNotFound = "not_found"
+	StatsFailed   = "failed"
+)
+
+type BranchStatsBatchResult struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Version int64  `json:"version,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// UpdateLogoRequest hanya untuk dokumentasi OpenAPI, file dibaca dari form field "logo"
+type UpdateLogoRequest struct {
+	Logo string `json:"logo" format:"binary" validate:"required"`
+}
+
+// UpdateBranchRequest adalah body PATCH /branch/:id, field nil tidak diubah
+type UpdateBranchRequest struct {
+	Name       *string `json:"name" validate:"omitempty,max=150"`
+	Address    *string `json:"address" validate:"omitempty,max=255"`
+	Email      *string `json:"email" validate:"omitempty,email,max=150"`
+	City       *string `json:"city" validate:"omitempty,max=100"`
+	Contact    *string `json:"contact" validate:"omitempty,phone_id,max=25"`
+	WhatsApp   *string `json:"whatsapp" validate:"omitempty,whatsapp_id,max=50"`
+	Coordinate *string `json:"coordinate" validate:"omitempty,latlng,max=255"`
+	Sipa       *string `json:"sipa" validate:"omitempty,sipa,max=255"`
+	Timezone   *string `json:"timezone" validate:"omitempty,iana_timezone,max=200"`
+	RoundPPN   *string `json:"roundPpn" validate:"omitempty,roundppn"`
+	// Upline memindah cabang ke manajemen lain
+	Upline *string `json:"upline" validate:"omitempty,branch_id"`
+}
+
+// Interval agregasi riwayat statistik
+const (
+	StatsDaily   = "daily"
+	StatsWeekly  = "weekly"
+	StatsMonthly = "monthly"
+)
+
+// BranchStatsPoint adalah rata-rata snapshot statistik cabang dalam satu periode. Period
+// adalah tanggal awal periode: Senin untuk weekly, tanggal 1 untuk monthly
+type BranchStatsPoint struct {
+	BranchID          string  `json:"-"`
+	Period            string  `json:"period"`
+	Samples           int64   `json:"samples"`
+	AvgGuest          float64 `json:"avgGuest"`
+	AvgTransaction    float64 `json:"avgTransaction"`
+	GuestCommentRate  float64 `json:"guestCommentRate"`
+	GuestTotalByMonth float64 `json:"guestTotalByMonth"`
+	TrxTotalByMonth   float64 `json:"trxTotalByMonth"`
+	RateReceptionist  float64 `json:"rateReceptionist"`
+	RateDoctor        float64 `json:"rateDoctor"`
+	RateBeautician    float64 `json:"rateBeautician"`
+}
+
+// BranchStatsHistoryResponse adalah riwayat statistik satu cabang, periode tanpa push tidak ada di Points
+type BranchStatsHistoryResponse struct {
+	ID       string              `json:"id"`
+	Name     string              `json:"name"`
+	Interval string              `json:"interval"`
+	From     string              `json:"from"`
+	To       string              `json:"to"`
+	Points   []*BranchStatsPoint `json:"points"`
+}
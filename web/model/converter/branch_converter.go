@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"backend/web/entity"
+	"backend/web/model"
+)
+
+func BranchToResponse(branch *entity.Branch) *model.BranchResponse {
+	return &model.BranchResponse{
+		ID:            branch.ID,
+		Name:          branch.NamaCabang,
+		Address:       branch.Alamat,
+		City:          branch.Kota,
+		Contact:       branch.Kontak,
+		Email:         branch.Email,
+		WhatsApp:      branch.NoWhatsapp,
+		Coordinate:    branch.Koordinat,
+		Sipa:          branch.Sipa,
+		Timezone:      branch.Datetime,
+		RoundPPN:      branch.RoundPPN,
+		PPN:           branch.PPN,
+		BpomMode:      branch.BpomMode,
+		Upline:        branch.Upline,
+		IsManagement:  branch.IsManajemen != nil && *branch.IsManajemen,
+		IsPaid:        branch.IsPaid != nil && *branch.IsPaid,
+		ExpireDate:    branch.ExpireDate,
+		Logo:          branch.Logo,
+		LogoThumbnail: branch.LogoThumbnail,
+		Version:       branch.Version,
+		Stats:         BranchStatsToResponse(branch),
+	}
+}
+
+func BranchStatsToResponse(branch *entity.Branch) model.BranchStatsResponse {
+	return model.BranchStatsResponse{
+		AvgGuest:             valueOf(branch.AvgGuest),
+		AvgTransaction:       valueOf(branch.AvgTransaction),
+		GuestCommentRate:     valueOf(branch.GuestCommentRate),
+		GuestTotalByMonth:    valueOf(branch.GuestTotalByMonth),
+		TrxTotalByMonth:      valueOf(branch.TrxTotalByMonth),
+		RateReceptionist:     valueOf(branch.RateReceptionist),
+		RateDoctor:           valueOf(branch.RateDoctor),
+		RateBeautician:       valueOf(branch.RateBeautician),
+		TopProduct:           TopListToResponse(branch.TopProduct),
+		TopServices:          TopListToResponse(branch.TopServices),
+		TopProfAction:        TopListToResponse(branch.TopProfAction),
+		ChartActivityByMonth: ChartToResponse(branch.ChartActivityByMonth),
+		ChartSalesByYear:     ChartToResponse(branch.ChartSalesByYear),
+		LastUpdate:           branch.LastUpdateDashboard,
+		MeasuredAt:           branch.StatsMeasuredAt,
+	}
+}
+
+func TopListToResponse(list entity.TopList) []model.TopItem {
+	if list == nil {
+		return nil
+	}
+	items := make([]model.TopItem, 0, len(list))
+	for _, item := range list {
+		items = append(items, model.TopItem{Name: item.Name, Total: item.Total})
+	}
+	return items
+}
+
+func ChartToResponse(chart *entity.Chart) *model.Chart {
+	if chart == nil {
+		return nil
+	}
+	response := &model.Chart{Labels: chart.Labels, Series: make([]model.ChartSeries, 0, len(chart.Series))}
+	for _, series := range chart.Series {
+		response.Series = append(response.Series, model.ChartSeries{Name: series.Name, Data: series.Data})
+	}
+	return response
+}
+
+// valueOf mengembalikan nilai pointer, atau nilai nol jika kolom NULL
+func valueOf[T any](value *T) T {
+	if value == nil {
+		var zero T
+		return zero
+	}
+	return *value
+}
+
+func TopListToEntity(items []model.TopItem) entity.TopList {
+	if items == nil {
+		return nil
+	}
+	list := make(entity.TopList, 0, len(items))
+	for _, item := range items {
+		list = append(list, entity.TopItem{Name: item.Name, Total: item.Total})
+	}
+	return list
+}
+
+func ChartToEntity(chart *model.Chart) *entity.Chart {
+	if chart == nil {
+		return nil
+	}
+	result := &entity.Chart{Labels: chart.Labels, Series: make([]entity.ChartSeries, 0, len(chart.Series))}
+	for _, series := range chart.Series {
+		result.Series = append(result.Series, entity.ChartSeries{Name: series.Name, Data: series.Data})
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"backend/web/entity"
+	"backend/web/model"
+	"strings"
+)
+
+func WebhookToResponse(subscription *entity.WebhookSubscription) *model.WebhookResponse {
+	return &model.WebhookResponse{
+		ID:          subscription.ID,
+		URL:         subscription.URL,
+		Events:      strings.Split(subscription.Events, ","),
+		Description: subscription.Description,
+		IsActive:    subscription.IsActive,
+		CreatedAt:   subscription.CreatedAt,
+		UpdatedAt:   subscription.UpdatedAt,
+	}
+}
+
+func WebhookDeliveryToResponse(delivery *entity.WebhookDelivery) *model.WebhookDeliveryResponse {
+	response := &model.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastResponse:   delivery.LastResponse,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	// jadwal kirim berikutnya hanya bermakna untuk delivery yang masih pending
+	if delivery.Status == entity.WebhookPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return response
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CreateWebhookRequest adalah body POST /webhook. Secret kosong dibuatkan otomatis
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,http_url,max=2000"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16,max=100"`
+	Events      []string `json:"events" validate:"required,min=1,dive,webhook_event"`
+	Description string   `json:"description" validate:"omitempty,max=255"`
+}
+
+// UpdateWebhookRequest adalah body PATCH /webhook/:id, field nil tidak diubah
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" validate:"omitempty,http_url,max=2000"`
+	Secret      *string  `json:"secret" validate:"omitempty,min=16,max=100"`
+	Events      []string `json:"events" validate:"omitempty,min=1,dive,webhook_event"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	IsActive    *bool    `json:"isActive"`
+}
+
+// WebhookResponse tidak membawa secret kecuali saat subscription dibuat
+type WebhookResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	Events      []string  `json:"events"`
+	Description *string   `json:"description"`
+	IsActive    bool      `json:"isActive"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode"`
+	LastResponse   *string         `json:"lastResponse"`
+	LastError      *string         `json:"lastError"`
+	DeliveredAt    *time.Time      `json:"deliveredAt"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"backend/core/cache"
+	"backend/core/outbox"
+	"backend/core/repo"
+	"backend/web/entity"
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// BranchCache adalah read-through cache di depan BranchsRepository untuk lookup cabang
+// per id. Cache nil berarti cache dimatikan dan lookup langsung ke database
+type BranchCache struct {
+	Cache      *cache.Cache[entity.Branch]
+	Repository *BranchsRepository
+	Log        *logrus.Logger
+}
+
+func NewBranchCache(c *cache.Cache[entity.Branch], repository *BranchsRepository, log *logrus.Logger) *BranchCache {
+	return &BranchCache{
+		Cache:      c,
+		Repository: repository,
+		Log:        log,
+	}
+}
+
+// FindByID mengambil cabang dari cache, atau dari database lalu disimpan ke cache.
+// Cabang yang tidak ditemukan tidak di-cache supaya cabang baru langsung terlihat
+func (c *BranchCache) FindByID(db *gorm.DB, id string) (*entity.Branch, error) {
+	load := func(ctx context.Context) (entity.Branch, error) {
+		branch := entity.Branch{}
+		err := c.Repository.FindOne(db.WithContext(ctx), &branch, repo.WithEqual("id", id))
+		return branch, err
+	}
+
+	ctx := db.Statement.Context
+	if c.Cache == nil {
+		branch, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &branch, nil
+	}
+
+	branch, err := c.Cache.Get(ctx, id, load)
+	if err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+// Invalidate menghapus cabang dari cache, dipanggil setelah transaksi yang mengubah cabang commit
+func (c *BranchCache) Invalidate(ctx context.Context, ids ...string) {
+	if c.Cache == nil || len(ids) == 0 {
+		return
+	}
+	c.Cache.Invalidate(ctx, ids...)
+}
+
+// HandleEvent adalah handler outbox yang menghapus cabang dari cache untuk setiap event
+// branch.*. Satu event hanya diproses satu relay, jadi LRU di proses lain tetap menunggu
+// TTL lokal habis, tapi Remote yang dipakai bersama selalu ikut terhapus
+func (c *BranchCache) HandleEvent(ctx context.Context, event *outbox.Event) error {
+	if strings.HasPrefix(event.Type, "branch.") {
+		c.Invalidate(ctx, event.AggregateID)
+	}
+	return nil
+}
@@ -3,8 +3,11 @@ package repository
 import (
 	"backend/core/repo"
 	"backend/web/entity"
+	"backend/web/model"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type BranchsRepository struct {
@@ -17,3 +20,56 @@ func NewBranchsRepository(log *logrus.Logger) *BranchsRepository {
 		Log: log,
 	}
 }
+
+// CountByState menghitung jumlah cabang per status (trial, paid, expired, deleted)
+// serta jumlah manajemen yang masih aktif
+func (r *BranchsRepository) CountByState(db *gorm.DB) (*model.BranchStateCount, error) {
+	const (
+		isBranch  = "(is_manajemen = 0 OR is_manajemen IS NULL)"
+		notDelete = "(is_delete = 0 OR is_delete IS NULL)"
+		notPaid   = "(is_paid = 0 OR is_paid IS NULL)"
+	)
+
+	today := time.Now().Format("2006-01-02")
+	result := new(model.BranchStateCount)
+
+	err := repo.Conn(db).Model(new(entity.Branch)).
+		Select(
+			"COALESCE(SUM(CASE WHEN "+isBranch+" AND "+notDelete+" AND "+notPaid+" AND (expire_date IS NULL OR expire_date >= ?) THEN 1 ELSE 0 END), 0) AS trial, "+
+				"COALESCE(SUM(CASE WHEN "+isBranch+" AND "+notDelete+" AND is_paid = 1 THEN 1 ELSE 0 END), 0) AS paid, "+
+				"COALESCE(SUM(CASE WHEN "+isBranch+" AND "+notDelete+" AND "+notPaid+" AND expire_date < ? THEN 1 ELSE 0 END), 0) AS expired, "+
+				"COALESCE(SUM(CASE WHEN "+isBranch+" AND is_delete = 1 THEN 1 ELSE 0 END), 0) AS deleted, "+
+				"COALESCE(SUM(CASE WHEN is_manajemen = 1 AND "+notDelete+" THEN 1 ELSE 0 END), 0) AS managements",
+			today, today,
+		).
+		Scan(result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateStats mengganti statistik dashboard cabang yang belum dihapus dan menaikkan version,
+// hanya jika measuredAt lebih baru dari push terakhir. Kondisi dicek di query UPDATE supaya
+// dua push yang balapan tidak saling menimpa. false jika tidak ada baris yang cocok
+// (cabang tidak ada atau push basi)
+func (r *BranchsRepository) UpdateStats(db *gorm.DB, id string, measuredAt time.Time, updates map[string]interface{}) (bool, error) {
+	values := make(map[string]interface{}, len(updates)+2)
+	for column, value := range updates {
+		values[column] = value
+	}
+	// stats_measured_at selalu berubah, jadi RowsAffected bisa dipakai untuk cek kecocokan
+	values["stats_measured_at"] = measuredAt
+	values[repo.VersionColumn] = gorm.Expr(repo.VersionColumn + " + 1")
+
+	affected, err := r.UpdateBulk(db, values,
+		repo.WithEqual("id", id),
+		repo.NotDeleted(),
+		repo.WithWhere("stats_measured_at IS NULL OR stats_measured_at < ?", measuredAt),
+	)
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
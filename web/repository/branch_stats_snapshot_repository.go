@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"backend/core/repo"
+	"backend/web/entity"
+	"backend/web/model"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// statsBuckets adalah ekspresi tanggal awal periode per interval, hanya nilai dari map ini
+// yang disambung ke SQL
+var statsBuckets = map[string]string{
+	model.StatsDaily:   "period",
+	model.StatsWeekly:  "DATE_SUB(period, INTERVAL WEEKDAY(period) DAY)",
+	model.StatsMonthly: "DATE_SUB(period, INTERVAL DAYOFMONTH(period) - 1 DAY)",
+}
+
+type BranchStatsSnapshotRepository struct {
+	repo.Repository[entity.BranchStatsSnapshot]
+	Log *logrus.Logger
+}
+
+func NewBranchStatsSnapshotRepository(log *logrus.Logger) *BranchStatsSnapshotRepository {
+	return &BranchStatsSnapshotRepository{
+		Log: log,
+	}
+}
+
+// Aggregate menghitung rata-rata snapshot per cabang per periode untuk tanggal from sampai to
+// (inklusif), urut berdasarkan cabang lalu periode
+func (r *BranchStatsSnapshotRepository) Aggregate(db *gorm.DB, branchIDs []string, from, to time.Time, interval string) ([]*model.BranchStatsPoint, error) {
+	bucket, ok := statsBuckets[interval]
+	if !ok {
+		return nil, fmt.Errorf("unknown stats interval %q", interval)
+	}
+
+	period := "DATE_FORMAT(" + bucket + ", '%Y-%m-%d')"
+	points := make([]*model.BranchStatsPoint, 0)
+	err := repo.Conn(db).Model(new(entity.BranchStatsSnapshot)).
+		Select(
+			"branch_id, "+period+" AS period, COUNT(*) AS samples, "+
+				"AVG(avg_guest) AS avg_guest, AVG(avg_transaction) AS avg_transaction, "+
+				"AVG(guest_comment_rate) AS guest_comment_rate, AVG(guest_total_by_month) AS guest_total_by_month, "+
+				"AVG(trx_total_by_month) AS trx_total_by_month, AVG(rate_receptionist) AS rate_receptionist, "+
+				"AVG(rate_doctor) AS rate_doctor, AVG(rate_beautician) AS rate_beautician",
+		).
+		Where("branch_id IN ?", branchIDs).
+		Where("period BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		// GROUP BY memakai ekspresi, bukan alias, karena alias period sama dengan nama kolom
+		Group("branch_id, " + period).
+		Order("branch_id, period").
+		Scan(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"backend/core/repo"
+	"backend/web/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookSubscriptionRepository struct {
+	repo.Repository[entity.WebhookSubscription]
+	Log *logrus.Logger
+}
+
+func NewWebhookSubscriptionRepository(log *logrus.Logger) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{
+		Log: log,
+	}
+}
+
+type WebhookDeliveryRepository struct {
+	repo.Repository[entity.WebhookDelivery]
+	Log *logrus.Logger
+}
+
+func NewWebhookDeliveryRepository(log *logrus.Logger) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		Log: log,
+	}
+}
+
+// ClaimPending mengambil delivery pending yang sudah jadwalnya dan menandainya dengan
+// locked_until, supaya proses lain (prefork atau container lain) tidak mengirim delivery yang sama
+func (r *WebhookDeliveryRepository) ClaimPending(db *gorm.DB, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	deliveries := make([]entity.WebhookDelivery, 0)
+	now := time.Now()
+
+	err := repo.ExecuteInTransaction(db.Statement.Context, db, func(tx *gorm.DB) error {
+		err := r.FindMany(tx, &deliveries,
+			repo.WithEqual("status", entity.WebhookPending),
+			repo.WithWhere("next_attempt_at <= ?", now),
+			repo.WithWhere("locked_until IS NULL OR locked_until <= ?", now),
+			repo.WithOrder("next_attempt_at"),
+			repo.WithLimit(limit),
+			func(db *gorm.DB) *gorm.DB {
+				return db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked})
+			},
+		)
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]string, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		_, err = r.UpdateBulk(tx, map[string]interface{}{"locked_until": now.Add(lease)}, repo.WithWhere("id IN ?", ids))
+		return err
+	})
+	return deliveries, err
+}
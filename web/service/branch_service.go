@@ -1,11 +1,19 @@
 package service
 
 import (
+	"backend/core/outbox"
 	"backend/core/repo"
+	"backend/core/storage"
+	"backend/core/utils"
 	"backend/web/entity"
 	"backend/web/model"
+	"backend/web/model/converter"
 	"backend/web/repository"
 	"context"
+	"errors"
+	"mime/multipart"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -19,20 +27,38 @@ type BranchsService struct {
 	DB               *gorm.DB
 	Log              *logrus.Logger
 	Validate         *validator.Validate
+	Defaults         func() model.ProvisioningDefaults
+	Upload           func() utils.UploadConfig
+	Storage          storage.Storage
+	UnitOfWork       *repo.UnitOfWork
+	BranchCache      *repository.BranchCache
 	BranchRepository *repository.BranchsRepository
+	StatsRepository  *repository.BranchStatsSnapshotRepository
 }
 
 func NewBrandsService(
 	db *gorm.DB,
 	logger *logrus.Logger,
 	validate *validator.Validate,
+	defaults func() model.ProvisioningDefaults,
+	upload func() utils.UploadConfig,
+	store storage.Storage,
+	unitOfWork *repo.UnitOfWork,
+	branchCache *repository.BranchCache,
 	branchRepository *repository.BranchsRepository,
+	statsRepository *repository.BranchStatsSnapshotRepository,
 ) *BranchsService {
 	return &BranchsService{
 		DB:               db,
 		Log:              logger,
 		Validate:         validate,
+		Defaults:         defaults,
+		Upload:           upload,
+		Storage:          store,
+		UnitOfWork:       unitOfWork,
+		BranchCache:      branchCache,
 		BranchRepository: branchRepository,
+		StatsRepository:  statsRepository,
 	}
 }
 
@@ -40,11 +66,11 @@ func (s *BranchsService) AddNewManagement(ctx context.Context, request *model.Cr
 
 	management := &entity.Branch{}
 
-	err := repo.ExecuteInTransaction(ctx, s.DB, func(tx *gorm.DB) error {
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
 		// validasi request
 		if err := s.Validate.Struct(request); err != nil {
-			s.Log.Warnf("Invalid request body : %+v", err)
-			return fiber.ErrBadRequest
+			s.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+			return err
 		}
 
 		management.ID = request.ID
@@ -52,23 +78,24 @@ func (s *BranchsService) AddNewManagement(ctx context.Context, request *model.Cr
 		management.Alamat = request.Address
 		management.Email = request.Email
 
-		// 🧱 Field default/hardcoded (mengikuti contoh data)
-		management.Kota = "Bandung"
-		management.Kontak = "-"
+		// 🧱 Field default (dari section provisioning di config)
+		defaults := s.Defaults()
+		management.Kota = defaults.Kota
+		management.Kontak = defaults.Kontak
 		management.Koordinat = ""
 		management.Sipa = ""
 		management.IsPrivate = false
 		management.Pettycash = 0
-		management.BpomMode = true
-		management.PPN = 0
-		timezone := "Asia/Jakarta"
+		management.BpomMode = defaults.BpomMode
+		management.PPN = defaults.PPN
+		timezone := defaults.Timezone
 		management.Datetime = &timezone
 		management.Upline = "0"
 		isManajemen := true
 		management.IsManajemen = &isManajemen
-		round := "up"
+		round := defaults.RoundPPN
 		management.RoundPPN = &round
-		expDate, _ := time.Parse("2006-01-02", "2030-01-01")
+		expDate, _ := time.Parse("2006-01-02", defaults.ExpireDate)
 		management.ExpireDate = &expDate
 		isPaid := false
 		dev := false
@@ -88,17 +115,22 @@ func (s *BranchsService) AddNewManagement(ctx context.Context, request *model.Cr
 		management.IDKlien = uuid.New().String()
 		management.AccessStatus = new(bool)
 
+		management.Version = 1
+
 		if err := s.BranchRepository.Create(tx, management); err != nil {
-			s.Log.Warnf("Failed to create branch : %+v", err)
-			return fiber.ErrInternalServerError
+			s.Log.WithContext(ctx).Warnf("Failed to create branch : %+v", err)
+			return err
 		}
-		return nil
+
+		event := model.BranchCreatedEvent{Branch: converter.BranchToResponse(management)}
+		return outbox.Record(tx, model.EventBranchCreated, management.ID, event)
 	})
 
 	if err != nil {
-		s.Log.Warnf("Message : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		s.Log.WithContext(ctx).Warnf("Message : %+v", err)
+		return nil, err
 	}
+	s.BranchCache.Invalidate(ctx, management.ID)
 
 	return &model.CreateManagementResponse{
 		ID:      management.ID,
@@ -111,11 +143,11 @@ func (s *BranchsService) AddNewBranch(ctx context.Context, request *model.Create
 
 	management := &entity.Branch{}
 
-	err := repo.ExecuteInTransaction(ctx, s.DB, func(tx *gorm.DB) error {
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
 		// validasi request
 		if err := s.Validate.Struct(request); err != nil {
-			s.Log.Warnf("Invalid request body : %+v", err)
-			return fiber.ErrBadRequest
+			s.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+			return err
 		}
 
 		management.ID = request.ID
@@ -124,22 +156,23 @@ func (s *BranchsService) AddNewBranch(ctx context.Context, request *model.Create
 		management.Email = request.Email
 		management.Upline = request.Upline
 
-		// 🧱 Field default/hardcoded (mengikuti contoh data)
-		management.Kota = "Bandung"
-		management.Kontak = "-"
+		// 🧱 Field default (dari section provisioning di config)
+		defaults := s.Defaults()
+		management.Kota = defaults.Kota
+		management.Kontak = defaults.Kontak
 		management.Koordinat = ""
 		management.Sipa = ""
 		management.IsPrivate = false
 		management.Pettycash = 0
-		management.BpomMode = true
-		management.PPN = 0
-		timezone := "Asia/Jakarta"
+		management.BpomMode = defaults.BpomMode
+		management.PPN = defaults.PPN
+		timezone := defaults.Timezone
 		management.Datetime = &timezone
 		isManajemen := false
 		management.IsManajemen = &isManajemen
-		round := "up"
+		round := defaults.RoundPPN
 		management.RoundPPN = &round
-		expDate, _ := time.Parse("2006-01-02", "2030-01-01")
+		expDate, _ := time.Parse("2006-01-02", defaults.ExpireDate)
 		management.ExpireDate = &expDate
 		isPaid := true
 		dev := false
@@ -159,17 +192,45 @@ func (s *BranchsService) AddNewBranch(ctx context.Context, request *model.Create
 		management.IDKlien = uuid.New().String()
 		management.AccessStatus = new(bool)
 
+		// field opsional dari request menimpa nilai default
+		if request.City != "" {
+			management.Kota = request.City
+		}
+		if request.Contact != "" {
+			management.Kontak = request.Contact
+		}
+		if request.WhatsApp != "" {
+			management.NoWhatsapp = &request.WhatsApp
+		}
+		if request.Coordinate != "" {
+			management.Koordinat = request.Coordinate
+		}
+		if request.Sipa != "" {
+			management.Sipa = request.Sipa
+		}
+		if request.Timezone != "" {
+			management.Datetime = &request.Timezone
+		}
+		if request.RoundPPN != "" {
+			management.RoundPPN = &request.RoundPPN
+		}
+
+		management.Version = 1
+
 		if err := s.BranchRepository.Create(tx, management); err != nil {
-			s.Log.Warnf("Failed to create branch : %+v", err)
-			return fiber.ErrInternalServerError
+			s.Log.WithContext(ctx).Warnf("Failed to create branch : %+v", err)
+			return err
 		}
-		return nil
+
+		event := model.BranchCreatedEvent{Branch: converter.BranchToResponse(management)}
+		return outbox.Record(tx, model.EventBranchCreated, management.ID, event)
 	})
 
 	if err != nil {
-		s.Log.Warnf("Message : %+v", err)
-		return nil, fiber.ErrInternalServerError
+		s.Log.WithContext(ctx).Warnf("Message : %+v", err)
+		return nil, err
 	}
+	s.BranchCache.Invalidate(ctx, management.ID)
 
 	return &model.CreateBranchResponse{
 		ID:      management.ID,
@@ -177,3 +238,355 @@ func (s *BranchsService) AddNewBranch(ctx context.Context, request *model.Create
 		Address: management.Alamat,
 	}, nil
 }
+
+// GetBranch membaca cabang lewat BranchCache
+func (s *BranchsService) GetBranch(ctx context.Context, id string) (*model.BranchResponse, error) {
+	branch, err := s.BranchCache.FindByID(s.DB.WithContext(ctx), id)
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to find branch : %+v", err)
+		return nil, err
+	}
+
+	return converter.BranchToResponse(branch), nil
+}
+
+// ListBranches mengambil daftar cabang dengan filter, sort dan fields dari query string
+// (lihat repo.ParseQuery), default urut berdasarkan id
+func (s *BranchsService) ListBranches(ctx context.Context, query map[string]string, page, size int) ([]*model.BranchResponse, *utils.PageMetadata, error) {
+	db := s.DB.WithContext(ctx)
+
+	opts, err := s.BranchRepository.ParseQuery(db, query)
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Invalid list query : %+v", err)
+		return nil, nil, err
+	}
+	if query["sort"] == "" {
+		opts = append(opts, repo.WithOrder("id"))
+	}
+
+	branches := make([]entity.Branch, 0)
+	total, err := s.BranchRepository.FindWithPagination(db, &branches, page, size, opts...)
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to list branches : %+v", err)
+		return nil, nil, err
+	}
+
+	responses := make([]*model.BranchResponse, 0, len(branches))
+	for i := range branches {
+		responses = append(responses, converter.BranchToResponse(&branches[i]))
+	}
+
+	return responses, utils.NewPageMetadata(page, size, total), nil
+}
+
+// ListBranchesCursor sama seperti ListBranches tapi memakai pagination cursor (keyset),
+// tanpa COUNT dan OFFSET sehingga tetap cepat di halaman yang dalam
+func (s *BranchsService) ListBranchesCursor(ctx context.Context, query map[string]string, cursor string, size int) ([]*model.BranchResponse, *utils.CursorMetadata, error) {
+	db := s.DB.WithContext(ctx)
+
+	sort, err := s.BranchRepository.ParseSort(db, query["sort"])
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Invalid list query : %+v", err)
+		return nil, nil, err
+	}
+
+	// sort diurus FindWithCursor, dan kolom sort harus ikut diambil untuk membuat cursor
+	filters := make(map[string]string, len(query))
+	for key, value := range query {
+		filters[key] = value
+	}
+	delete(filters, "sort")
+	if fields := filters["fields"]; fields != "" {
+		for _, column := range append(sort, repo.SortColumn{Name: "id"}) {
+			if !slices.Contains(strings.Split(fields, ","), column.Name) {
+				fields += "," + column.Name
+			}
+		}
+		filters["fields"] = fields
+	}
+
+	opts, err := s.BranchRepository.ParseQuery(db, filters)
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Invalid list query : %+v", err)
+		return nil, nil, err
+	}
+
+	branches := make([]entity.Branch, 0)
+	next, prev, err := s.BranchRepository.FindWithCursor(db, &branches, cursor, size, sort, opts...)
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to list branches : %+v", err)
+		return nil, nil, err
+	}
+
+	responses := make([]*model.BranchResponse, 0, len(branches))
+	for i := range branches {
+		responses = append(responses, converter.BranchToResponse(&branches[i]))
+	}
+
+	return responses, utils.NewCursorMetadata(size, next, prev), nil
+}
+
+// UpdateBranch mengubah field yang dikirim saja, dan hanya jika version di database
+// masih sama dengan version dari header If-Match
+func (s *BranchsService) UpdateBranch(ctx context.Context, id string, version int64, request *model.UpdateBranchRequest) (*model.BranchResponse, error) {
+	branch := new(entity.Branch)
+
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		// validasi request
+		if err := s.Validate.Struct(request); err != nil {
+			s.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+			return err
+		}
+
+		updates, changes := branchUpdates(request)
+		if len(updates) == 0 {
+			return utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Tidak ada field yang diubah")
+		}
+
+		if err := s.BranchRepository.FindOne(tx, branch, repo.WithEqual("id", id)); err != nil {
+			s.Log.WithContext(ctx).Warnf("Failed to find branch : %+v", err)
+			return err
+		}
+		if branch.Version != version {
+			return utils.NewAppError(fiber.StatusPreconditionFailed, utils.ErrCodePreconditionFailed, "")
+		}
+
+		previousUpline := branch.Upline
+		moved := request.Upline != nil && *request.Upline != previousUpline
+		if moved {
+			if err := s.checkUpline(tx, branch, *request.Upline); err != nil {
+				return err
+			}
+		}
+
+		// cek version dilakukan lagi di query UPDATE, jadi update yang balapan tetap ditolak
+		err := s.BranchRepository.UpdateWithVersion(tx, updates, version, repo.WithEqual("id", id))
+		if errors.Is(err, repo.ErrVersionConflict) {
+			return utils.WrapAppError(err, fiber.StatusPreconditionFailed, utils.ErrCodePreconditionFailed, "")
+		}
+		if err != nil {
+			s.Log.WithContext(ctx).Warnf("Failed to update branch : %+v", err)
+			return err
+		}
+
+		if err := s.BranchRepository.FindOne(tx, branch, repo.WithEqual("id", id)); err != nil {
+			return err
+		}
+
+		event := model.BranchUpdatedEvent{Branch: converter.BranchToResponse(branch), Changes: changes}
+		if err := outbox.Record(tx, model.EventBranchUpdated, branch.ID, event); err != nil {
+			return err
+		}
+		if moved {
+			moved := model.BranchMovedManagementEvent{ID: branch.ID, From: previousUpline, To: branch.Upline}
+			return outbox.Record(tx, model.EventBranchMovedManagement, branch.ID, moved)
+		}
+		return nil
+	})
+
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Message : %+v", err)
+		return nil, err
+	}
+	// event outbox juga meng-invalidate, ini supaya proses ini langsung membaca data baru
+	s.BranchCache.Invalidate(ctx, id)
+
+	return converter.BranchToResponse(branch), nil
+}
+
+// UpdateLogo menyimpan file logo baru beserta thumbnail, mengganti URL di kolom logo lalu
+// menghapus logo lama. requestBaseURL dipakai untuk URL file jika storage menghasilkan URL relatif
+func (s *BranchsService) UpdateLogo(ctx context.Context, id string, file *multipart.FileHeader, requestBaseURL string) (*model.BranchResponse, error) {
+	branch := new(entity.Branch)
+	if err := s.BranchRepository.FindOne(s.DB.WithContext(ctx), branch, repo.WithEqual("id", id)); err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to find branch : %+v", err)
+		return nil, err
+	}
+
+	uploaded, err := utils.UploadFiles(ctx, s.Storage, []*multipart.FileHeader{file}, s.Upload())
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to save logo : %+v", err)
+		if errors.Is(err, utils.ErrFileRejected) {
+			return nil, utils.WrapAppError(err, fiber.StatusBadRequest, utils.ErrCodeBadRequest, err.Error())
+		}
+		return nil, err
+	}
+
+	// driver local tanpa upload.baseUrl menghasilkan URL relatif, lengkapi dengan host request
+	absoluteURL := func(fileURL string) string {
+		if strings.HasPrefix(fileURL, "/") {
+			return requestBaseURL + fileURL
+		}
+		return fileURL
+	}
+	logo := absoluteURL(uploaded[0].URL)
+	var logoThumbnail *string
+	if uploaded[0].ThumbnailURL != "" {
+		thumbnailURL := absoluteURL(uploaded[0].ThumbnailURL)
+		logoThumbnail = &thumbnailURL
+	}
+	previous := branch.Logo
+	previousThumbnail := branch.LogoThumbnail
+
+	err = s.UnitOfWork.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		// version tetap dinaikkan supaya ETag lama tidak bisa dipakai menimpa logo baru
+		updates := map[string]interface{}{"logo": logo, "logo_thumbnail": logoThumbnail}
+		err := s.BranchRepository.UpdateWithVersion(tx, updates, branch.Version, repo.WithEqual("id", id))
+		if errors.Is(err, repo.ErrVersionConflict) {
+			return utils.WrapAppError(err, fiber.StatusPreconditionFailed, utils.ErrCodePreconditionFailed, "")
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.BranchRepository.FindOne(tx, branch, repo.WithEqual("id", id)); err != nil {
+			return err
+		}
+
+		event := model.BranchUpdatedEvent{Branch: converter.BranchToResponse(branch), Changes: []string{"logo", "logoThumbnail"}}
+		return outbox.Record(tx, model.EventBranchUpdated, branch.ID, event)
+	})
+
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Message : %+v", err)
+		// logo baru tidak jadi dipakai, jangan tinggalkan file yatim
+		s.deleteLogoIfUnused(ctx, &logo, logoThumbnail, requestBaseURL)
+		return nil, err
+	}
+	s.BranchCache.Invalidate(ctx, id)
+
+	// file yang sama (hash sama) tidak dihapus walau diupload ulang
+	if previous != nil && *previous != logo {
+		s.deleteLogoIfUnused(ctx, previous, previousThumbnail, requestBaseURL)
+	}
+
+	return converter.BranchToResponse(branch), nil
+}
+
+// deleteLogoIfUnused menghapus file logo dan thumbnail jika tidak dipakai cabang lain.
+// Nama file berupa hash isi, jadi beberapa cabang bisa berbagi file logo yang sama.
+// Pemakaian dihitung per storage key, bukan URL lengkap, karena file yang sama bisa
+// tersimpan dengan host request atau baseUrl yang berbeda. URL relatif yang dilengkapi
+// host lain dari requestBaseURL tidak dihapus
+func (s *BranchsService) deleteLogoIfUnused(ctx context.Context, logo *string, thumbnail *string, requestBaseURL string) {
+	if logo == nil || *logo == "" {
+		return
+	}
+
+	// URL yang bukan milik storage ini tidak pernah dihapus
+	key, ok := storage.KeyOf(s.Storage, *logo, requestBaseURL)
+	if !ok {
+		return
+	}
+
+	used, err := s.BranchRepository.Count(s.DB.WithContext(ctx), repo.WithSuffix("logo", "/"+key))
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to check logo usage : %+v", err)
+		return
+	}
+	if used > 0 {
+		return
+	}
+
+	for _, fileURL := range []*string{logo, thumbnail} {
+		if fileURL == nil || *fileURL == "" {
+			continue
+		}
+		if err := utils.DeleteUploadedFile(ctx, s.Storage, *fileURL, requestBaseURL); err != nil {
+			s.Log.WithContext(ctx).Warnf("Failed to delete logo file : %+v", err)
+		}
+	}
+}
+
+// expiredLookback membatasi cabang expired yang dicek, supaya cabang yang sudah lama
+// expired tidak memicu event saat fitur ini pertama kali jalan
+const expiredLookbackDays = 7
+
+// EmitExpiredBranches menulis event BranchExpired untuk cabang trial (belum bayar) yang
+// masa aktifnya sudah lewat. Aman dipanggil berulang, event hanya ditulis sekali per tanggal expire
+func (s *BranchsService) EmitExpiredBranches(ctx context.Context) (int, error) {
+	db := s.DB.WithContext(ctx)
+	today := time.Now()
+
+	branches := make([]entity.Branch, 0)
+	err := s.BranchRepository.FindMany(db, &branches,
+		repo.WithWhere("(is_manajemen = 0 OR is_manajemen IS NULL) AND (is_paid = 0 OR is_paid IS NULL)"),
+		repo.WithWhere("expire_date < ? AND expire_date >= ?", today.Format("2006-01-02"), today.AddDate(0, 0, -expiredLookbackDays).Format("2006-01-02")),
+		repo.NotDeleted(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, branch := range branches {
+		event := model.BranchExpiredEvent{ID: branch.ID, Name: branch.NamaCabang, Upline: branch.Upline, ExpireDate: *branch.ExpireDate}
+		key := branch.ID + ":" + branch.ExpireDate.Format("2006-01-02")
+		if err := outbox.RecordOnce(db, key, model.EventBranchExpired, branch.ID, event); err != nil {
+			return 0, err
+		}
+	}
+	return len(branches), nil
+}
+
+// StartExpiredCheck menjalankan EmitExpiredBranches secara berkala di background.
+// Ticker dikembalikan supaya interval bisa diganti dengan Reset saat config berubah
+func StartExpiredCheck(service *BranchsService, interval time.Duration) *time.Ticker {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if _, err := service.EmitExpiredBranches(ctx); err != nil {
+				service.Log.Warnf("Failed to emit expired branch events : %+v", err)
+			}
+			cancel()
+		}
+	}()
+	return ticker
+}
+
+// branchUpdates memetakan field request yang diisi ke nama kolom tabel branchs,
+// beserta nama field JSON yang berubah untuk event BranchUpdated
+func branchUpdates(request *model.UpdateBranchRequest) (map[string]interface{}, []string) {
+	updates := map[string]interface{}{}
+	changes := make([]string, 0)
+	fields := []struct {
+		column string
+		field  string
+		value  *string
+	}{
+		{"nama_cabang", "name", request.Name},
+		{"alamat", "address", request.Address},
+		{"email", "email", request.Email},
+		{"kota", "city", request.City},
+		{"kontak", "contact", request.Contact},
+		{"no_whatsapp", "whatsapp", request.WhatsApp},
+		{"koordinat", "coordinate", request.Coordinate},
+		{"sipa", "sipa", request.Sipa},
+		{"datetime", "timezone", request.Timezone},
+		{"roundppn", "roundPpn", request.RoundPPN},
+		{"upline", "upline", request.Upline},
+	}
+	for _, f := range fields {
+		if f.value != nil {
+			updates[f.column] = *f.value
+			changes = append(changes, f.field)
+		}
+	}
+	return updates, changes
+}
+
+// checkUpline memastikan cabang bukan manajemen dan upline tujuan adalah manajemen yang aktif
+func (s *BranchsService) checkUpline(tx *gorm.DB, branch *entity.Branch, upline string) error {
+	if branch.IsManajemen != nil && *branch.IsManajemen {
+		return utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Manajemen tidak bisa dipindah ke manajemen lain")
+	}
+
+	exists, err := s.BranchRepository.Exists(tx, repo.WithEqual("id", upline), repo.WithEqual("is_manajemen", true), repo.NotDeleted())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Manajemen tujuan tidak ditemukan")
+	}
+	return nil
+}
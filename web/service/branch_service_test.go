@@ -0,0 +1,57 @@
+package service
+
+import (
+	"backend/core/storage"
+	"backend/web/repository"
+	"context"
+	"strings"
+	"testing"
+)
+
+const branchLogoTestTable = `CREATE TABLE branchs (
+	id TEXT PRIMARY KEY,
+	logo TEXT,
+	logo_thumbnail TEXT
+)`
+
+func TestDeleteLogoIfUnusedCountsByStorageKey(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, branchLogoTestTable)
+	log := newTestLogger()
+	store := storage.NewMemory("/uploads")
+	s := &BranchsService{DB: db, Log: log, Storage: store, BranchRepository: repository.NewBranchsRepository(log)}
+
+	for _, key := range []string{"ab/abcdef.png", "ab/abcdef_thumb.png"} {
+		if err := store.Put(ctx, key, strings.NewReader("png"), 3, "image/png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// file yang sama tersimpan dengan host request berbeda di dua cabang
+	logoA, thumbA := "http://host-a/uploads/ab/abcdef.png", "http://host-a/uploads/ab/abcdef_thumb.png"
+	logoB := "https://host-b/uploads/ab/abcdef.png"
+	if err := db.Exec("INSERT INTO branchs (id, logo) VALUES ('B', ?)", logoB).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s.deleteLogoIfUnused(ctx, &logoA, &thumbA, "http://host-a")
+	if len(store.Keys()) != 2 {
+		t.Fatalf("keys %v: logo still used by branch B was deleted", store.Keys())
+	}
+
+	// URL yang bukan milik storage diabaikan, termasuk path /uploads di host lain
+	if err := db.Exec("UPDATE branchs SET logo = NULL").Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, foreign := range []string{"https://elsewhere.example.com/ab/abcdef.png", "https://elsewhere.example.com/uploads/ab/abcdef.png"} {
+		s.deleteLogoIfUnused(ctx, &foreign, nil, "http://host-a")
+		if len(store.Keys()) != 2 {
+			t.Fatalf("keys %v: foreign URL %s deleted a stored file", store.Keys(), foreign)
+		}
+	}
+
+	s.deleteLogoIfUnused(ctx, &logoA, &thumbA, "http://host-a")
+	if len(store.Keys()) != 0 {
+		t.Fatalf("keys %v: unused logo and thumbnail not deleted", store.Keys())
+	}
+}
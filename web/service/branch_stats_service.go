@@ -0,0 +1,288 @@
+package service
+
+import (
+	"backend/core/outbox"
+	"backend/core/repo"
+	"backend/core/utils"
+	"backend/web/entity"
+	"backend/web/model"
+	"backend/web/model/converter"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxStatsClockSkew adalah toleransi jam pengirim yang lebih cepat dari server. measuredAt
+// yang terlalu jauh di depan akan membuat semua push berikutnya dianggap basi
+const maxStatsClockSkew = 5 * time.Minute
+
+// batas query riwayat statistik
+const (
+	defaultStatsRangeDays = 30
+	maxStatsRangeDays     = 366
+	maxCompareBranches    = 20
+)
+
+var errStatsStale = errors.New("stats older than last push")
+
+// UpdateStats mengganti statistik dashboard satu cabang. Push dengan measuredAt yang tidak
+// lebih baru dari push terakhir ditolak dengan 409
+func (s *BranchsService) UpdateStats(ctx context.Context, id string, request *model.BranchStatsRequest) (*model.BranchResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		s.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, err
+	}
+	if err := checkMeasuredAt(request.MeasuredAt); err != nil {
+		return nil, err
+	}
+
+	branch := new(entity.Branch)
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		return s.updateStats(tx, branch, id, request)
+	})
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Message : %+v", err)
+		return nil, err
+	}
+	s.BranchCache.Invalidate(ctx, id)
+
+	return converter.BranchToResponse(branch), nil
+}
+
+// UpdateStatsBatch mengganti statistik banyak cabang sekaligus. Body divalidasi utuh, lalu
+// setiap cabang disimpan di transaksi sendiri sehingga cabang yang basi atau tidak ada
+// tidak menggagalkan cabang lain
+func (s *BranchsService) UpdateStatsBatch(ctx context.Context, request *model.BranchStatsBatchRequest) ([]*model.BranchStatsBatchResult, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		s.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, err
+	}
+
+	results := make([]*model.BranchStatsBatchResult, 0, len(request.Items))
+	for i := range request.Items {
+		item := &request.Items[i]
+		result := &model.BranchStatsBatchResult{ID: item.ID}
+		results = append(results, result)
+
+		branch := new(entity.Branch)
+		err := checkMeasuredAt(item.Stats.MeasuredAt)
+		if err == nil {
+			err = s.UnitOfWork.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+				return s.updateStats(tx, branch, item.ID, &item.Stats)
+			})
+		}
+
+		switch {
+		case err == nil:
+			s.BranchCache.Invalidate(ctx, item.ID)
+			result.Status = model.StatsUpdated
+			result.Version = branch.Version
+		case errors.Is(err, errStatsStale):
+			result.Status = model.StatsStale
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			result.Status = model.StatsNotFound
+		default:
+			s.Log.WithContext(ctx).Warnf("Failed to update stats of branch %s : %+v", item.ID, err)
+			result.Status = model.StatsFailed
+			result.Message = "Gagal menyimpan statistik"
+			if appErr := utils.ToAppError(err); appErr.Status < fiber.StatusInternalServerError && appErr.Message != "" {
+				result.Message = appErr.Message
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (s *BranchsService) updateStats(tx *gorm.DB, branch *entity.Branch, id string, request *model.BranchStatsRequest) error {
+	updated, err := s.BranchRepository.UpdateStats(tx, id, request.MeasuredAt, branchStatsUpdates(request))
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// bedakan cabang yang tidak ada dengan push basi
+		exists, err := s.BranchRepository.Exists(tx, repo.WithEqual("id", id), repo.NotDeleted())
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return gorm.ErrRecordNotFound
+		}
+		return utils.WrapAppError(errStatsStale, fiber.StatusConflict, utils.ErrCodeConflict, "Statistik lebih lama dari statistik yang sudah tersimpan")
+	}
+
+	if err := s.BranchRepository.FindOne(tx, branch, repo.WithEqual("id", id)); err != nil {
+		return err
+	}
+
+	if err := s.StatsRepository.Create(tx, branchStatsSnapshot(id, request)); err != nil {
+		return err
+	}
+
+	event := model.BranchStatsUpdatedEvent{ID: branch.ID, Stats: converter.BranchStatsToResponse(branch)}
+	return outbox.Record(tx, model.EventBranchStatsUpdated, branch.ID, event)
+}
+
+// StatsHistory mengambil riwayat statistik satu cabang. rangeDate berformat YYYY-MM-DD atau
+// YYYY-MM-DD|YYYY-MM-DD (lihat utils.GetStartEndDate), kosong berarti 30 hari terakhir
+func (s *BranchsService) StatsHistory(ctx context.Context, id string, rangeDate string, interval string) (*model.BranchStatsHistoryResponse, error) {
+	histories, err := s.statsHistories(ctx, []string{id}, rangeDate, interval)
+	if err != nil {
+		return nil, err
+	}
+	return histories[0], nil
+}
+
+// CompareStats mengambil riwayat statistik beberapa cabang dengan periode yang sama,
+// urut sesuai ids
+func (s *BranchsService) CompareStats(ctx context.Context, ids []string, rangeDate string, interval string) ([]*model.BranchStatsHistoryResponse, error) {
+	if len(ids) == 0 || len(ids) > maxCompareBranches {
+		return nil, utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, fmt.Sprintf("ids harus berisi 1 sampai %d cabang", maxCompareBranches))
+	}
+	return s.statsHistories(ctx, ids, rangeDate, interval)
+}
+
+func (s *BranchsService) statsHistories(ctx context.Context, ids []string, rangeDate string, interval string) ([]*model.BranchStatsHistoryResponse, error) {
+	if interval == "" {
+		interval = model.StatsDaily
+	}
+	if interval != model.StatsDaily && interval != model.StatsWeekly && interval != model.StatsMonthly {
+		return nil, utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "interval harus daily, weekly atau monthly")
+	}
+
+	from, to, err := statsRange(rangeDate)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(ctx)
+	branches := make([]entity.Branch, 0)
+	err = s.BranchRepository.FindMany(db, &branches,
+		repo.WithEqual("id", ids), repo.NotDeleted(), repo.WithSelect("id", "nama_cabang"),
+	)
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to find branch : %+v", err)
+		return nil, err
+	}
+	names := make(map[string]string, len(branches))
+	for _, branch := range branches {
+		names[branch.ID] = branch.NamaCabang
+	}
+
+	histories := make([]*model.BranchStatsHistoryResponse, 0, len(ids))
+	byID := make(map[string]*model.BranchStatsHistoryResponse, len(ids))
+	missing := make([]string, 0)
+	for _, id := range ids {
+		name, ok := names[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		if _, ok := byID[id]; ok {
+			continue
+		}
+		history := &model.BranchStatsHistoryResponse{
+			ID:       id,
+			Name:     name,
+			Interval: interval,
+			From:     from.Format("2006-01-02"),
+			To:       to.Format("2006-01-02"),
+			Points:   make([]*model.BranchStatsPoint, 0),
+		}
+		histories = append(histories, history)
+		byID[id] = history
+	}
+	if len(missing) > 0 {
+		return nil, utils.NewAppError(fiber.StatusNotFound, utils.ErrCodeNotFound, "Cabang tidak ditemukan: "+strings.Join(missing, ","))
+	}
+
+	points, err := s.StatsRepository.Aggregate(db, ids, from, to, interval)
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to aggregate branch stats : %+v", err)
+		return nil, err
+	}
+	for _, point := range points {
+		if history, ok := byID[point.BranchID]; ok {
+			history.Points = append(history.Points, point)
+		}
+	}
+
+	return histories, nil
+}
+
+// statsRange membaca query range lewat utils.GetStartEndDate, maksimal maxStatsRangeDays hari
+func statsRange(rangeDate string) (time.Time, time.Time, error) {
+	if rangeDate == "" {
+		today := time.Now()
+		return today.AddDate(0, 0, -(defaultStatsRangeDays - 1)), today, nil
+	}
+
+	start, end, err := utils.GetStartEndDate(rangeDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, utils.WrapAppError(err, fiber.StatusBadRequest, utils.ErrCodeBadRequest, "range harus berformat YYYY-MM-DD atau YYYY-MM-DD|YYYY-MM-DD")
+	}
+	from, to := time.UnixMilli(start), time.UnixMilli(end)
+	if from.After(to) {
+		return time.Time{}, time.Time{}, utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Tanggal awal range tidak boleh setelah tanggal akhir")
+	}
+	if to.Sub(from) > maxStatsRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, fmt.Sprintf("range maksimal %d hari", maxStatsRangeDays))
+	}
+	return from, to, nil
+}
+
+// checkMeasuredAt menolak measuredAt kosong atau terlalu jauh di masa depan
+func checkMeasuredAt(measuredAt time.Time) error {
+	if measuredAt.IsZero() {
+		return utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "measuredAt wajib diisi")
+	}
+	if measuredAt.After(time.Now().Add(maxStatsClockSkew)) {
+		return utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "measuredAt tidak boleh di masa depan")
+	}
+	return nil
+}
+
+// branchStatsSnapshot membuat snapshot riwayat dari push statistik
+func branchStatsSnapshot(id string, request *model.BranchStatsRequest) *entity.BranchStatsSnapshot {
+	measuredAt := request.MeasuredAt.In(time.Local)
+	return &entity.BranchStatsSnapshot{
+		BranchID:          id,
+		Period:            time.Date(measuredAt.Year(), measuredAt.Month(), measuredAt.Day(), 0, 0, 0, 0, time.Local),
+		MeasuredAt:        measuredAt,
+		AvgGuest:          request.AvgGuest,
+		AvgTransaction:    request.AvgTransaction,
+		GuestCommentRate:  request.GuestCommentRate,
+		GuestTotalByMonth: request.GuestTotalByMonth,
+		TrxTotalByMonth:   request.TrxTotalByMonth,
+		RateReceptionist:  request.RateReceptionist,
+		RateDoctor:        request.RateDoctor,
+		RateBeautician:    request.RateBeautician,
+		CreatedAt:         time.Now(),
+	}
+}
+
+// branchStatsUpdates memetakan request ke kolom statistik, last_update_dashboard diisi
+// tanggal measuredAt
+func branchStatsUpdates(request *model.BranchStatsRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"avg_guest":               request.AvgGuest,
+		"avg_transaction":         request.AvgTransaction,
+		"guest_comment_rate":      request.GuestCommentRate,
+		"guest_total_by_month":    request.GuestTotalByMonth,
+		"trx_total_by_month":      request.TrxTotalByMonth,
+		"rate_receptionist":       request.RateReceptionist,
+		"rate_doctor":             request.RateDoctor,
+		"rate_beautician":         request.RateBeautician,
+		"top_product":             converter.TopListToEntity(request.TopProduct),
+		"top_services":            converter.TopListToEntity(request.TopServices),
+		"top_prof_action":         converter.TopListToEntity(request.TopProfAction),
+		"chart_activity_by_month": converter.ChartToEntity(request.ChartActivityByMonth),
+		"chart_sales_by_year":     converter.ChartToEntity(request.ChartSalesByYear),
+		"last_update_dashboard":   request.MeasuredAt.In(time.Local),
+	}
+}
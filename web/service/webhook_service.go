@@ -0,0 +1,375 @@
+package service
+
+import (
+	"backend/core/outbox"
+	"backend/core/repo"
+	"backend/core/utils"
+	"backend/core/webhook"
+	"backend/web/entity"
+	"backend/web/model"
+	"backend/web/model/converter"
+	"backend/web/repository"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// WebhookOptions mengatur pengiriman webhook. Delivery yang gagal dicoba ulang dengan
+// backoff eksponensial sampai MaxAttempts lalu berstatus dead (bisa dikirim ulang manual)
+type WebhookOptions struct {
+	BatchSize     int
+	MaxAttempts   int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// Lease adalah lama delivery di-klaim satu proses, harus lebih lama dari timeout HTTP
+	Lease time.Duration
+}
+
+type WebhookService struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	Sender                 *webhook.Sender
+	Options                WebhookOptions
+	SubscriptionRepository *repository.WebhookSubscriptionRepository
+	DeliveryRepository     *repository.WebhookDeliveryRepository
+}
+
+func NewWebhookService(
+	db *gorm.DB,
+	logger *logrus.Logger,
+	validate *validator.Validate,
+	sender *webhook.Sender,
+	options WebhookOptions,
+	subscriptionRepository *repository.WebhookSubscriptionRepository,
+	deliveryRepository *repository.WebhookDeliveryRepository,
+) *WebhookService {
+	return &WebhookService{
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		Sender:                 sender,
+		Options:                options,
+		SubscriptionRepository: subscriptionRepository,
+		DeliveryRepository:     deliveryRepository,
+	}
+}
+
+func (s *WebhookService) CreateSubscription(ctx context.Context, request *model.CreateWebhookRequest) (*model.WebhookResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		s.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, err
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		secret = newWebhookSecret()
+	}
+
+	subscription := &entity.WebhookSubscription{
+		ID:       uuid.NewString(),
+		URL:      request.URL,
+		Secret:   secret,
+		Events:   strings.Join(request.Events, ","),
+		IsActive: true,
+	}
+	if request.Description != "" {
+		subscription.Description = &request.Description
+	}
+
+	if err := s.SubscriptionRepository.Create(s.DB.WithContext(ctx), subscription); err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to create webhook : %+v", err)
+		return nil, err
+	}
+
+	// secret hanya ditampilkan sekali, saat subscription dibuat
+	response := converter.WebhookToResponse(subscription)
+	response.Secret = secret
+	return response, nil
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context, page, size int) ([]*model.WebhookResponse, *utils.PageMetadata, error) {
+	subscriptions := make([]entity.WebhookSubscription, 0)
+	total, err := s.SubscriptionRepository.FindWithPagination(s.DB.WithContext(ctx), &subscriptions, page, size, repo.WithOrder("created_at"))
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to list webhooks : %+v", err)
+		return nil, nil, err
+	}
+
+	responses := make([]*model.WebhookResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		responses = append(responses, converter.WebhookToResponse(&subscriptions[i]))
+	}
+	return responses, utils.NewPageMetadata(page, size, total), nil
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, id string) (*model.WebhookResponse, error) {
+	subscription := new(entity.WebhookSubscription)
+	if err := s.SubscriptionRepository.FindByID(s.DB.WithContext(ctx), subscription, id); err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to find webhook : %+v", err)
+		return nil, err
+	}
+	return converter.WebhookToResponse(subscription), nil
+}
+
+// UpdateSubscription mengubah field yang dikirim saja
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id string, request *model.UpdateWebhookRequest) (*model.WebhookResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		s.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, err
+	}
+
+	subscription := new(entity.WebhookSubscription)
+	err := repo.ExecuteInTransaction(ctx, s.DB, func(tx *gorm.DB) error {
+		if err := s.SubscriptionRepository.FindByID(tx, subscription, id, repo.WithLock(repo.ForUpdate)); err != nil {
+			return err
+		}
+
+		if request.URL != nil {
+			subscription.URL = *request.URL
+		}
+		if request.Secret != nil {
+			subscription.Secret = *request.Secret
+		}
+		if len(request.Events) > 0 {
+			subscription.Events = strings.Join(request.Events, ",")
+		}
+		if request.Description != nil {
+			subscription.Description = request.Description
+		}
+		if request.IsActive != nil {
+			subscription.IsActive = *request.IsActive
+		}
+		return s.SubscriptionRepository.Update(tx, subscription)
+	})
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to update webhook : %+v", err)
+		return nil, err
+	}
+
+	return converter.WebhookToResponse(subscription), nil
+}
+
+// DeleteSubscription menghapus subscription beserta riwayat delivery-nya
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	err := repo.ExecuteInTransaction(ctx, s.DB, func(tx *gorm.DB) error {
+		subscription := new(entity.WebhookSubscription)
+		if err := s.SubscriptionRepository.FindByID(tx, subscription, id); err != nil {
+			return err
+		}
+		if _, err := s.DeliveryRepository.DeleteWhere(tx, repo.WithEqual("subscription_id", id)); err != nil {
+			return err
+		}
+		return s.SubscriptionRepository.Delete(tx, subscription)
+	})
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to delete webhook : %+v", err)
+	}
+	return err
+}
+
+// ListDeliveries mengambil riwayat delivery satu subscription, terbaru lebih dulu.
+// status kosong berarti semua status
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID, status string, page, size int) ([]*model.WebhookDeliveryResponse, *utils.PageMetadata, error) {
+	db := s.DB.WithContext(ctx)
+
+	exists, err := s.SubscriptionRepository.Exists(db, repo.WithEqual("id", subscriptionID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	opts := []repo.QueryOption{repo.WithEqual("subscription_id", subscriptionID), repo.WithOrder("created_at DESC")}
+	if status != "" {
+		if !slices.Contains([]string{entity.WebhookPending, entity.WebhookDelivered, entity.WebhookDead}, status) {
+			return nil, nil, utils.NewAppError(fiber.StatusBadRequest, utils.ErrCodeBadRequest, "Status tidak valid")
+		}
+		opts = append(opts, repo.WithEqual("status", status))
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0)
+	total, err := s.DeliveryRepository.FindWithPagination(db, &deliveries, page, size, opts...)
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to list webhook deliveries : %+v", err)
+		return nil, nil, err
+	}
+
+	responses := make([]*model.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		responses = append(responses, converter.WebhookDeliveryToResponse(&deliveries[i]))
+	}
+	return responses, utils.NewPageMetadata(page, size, total), nil
+}
+
+// Redeliver menjadwalkan ulang delivery (termasuk yang sudah dead atau delivered)
+// untuk dikirim secepatnya dengan jatah percobaan baru
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDeliveryResponse, error) {
+	delivery := new(entity.WebhookDelivery)
+	err := repo.ExecuteInTransaction(ctx, s.DB, func(tx *gorm.DB) error {
+		if err := s.DeliveryRepository.FindOne(tx, delivery, repo.WithEqual("id", deliveryID), repo.WithEqual("subscription_id", subscriptionID), repo.WithLock(repo.ForUpdate)); err != nil {
+			return err
+		}
+
+		delivery.Status = entity.WebhookPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = time.Now()
+		delivery.LockedUntil = nil
+		return s.DeliveryRepository.Update(tx, delivery)
+	})
+	if err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to redeliver webhook : %+v", err)
+		return nil, err
+	}
+
+	return converter.WebhookDeliveryToResponse(delivery), nil
+}
+
+// HandleEvent adalah handler outbox.Relay: membuat delivery untuk setiap subscription aktif
+// yang berlangganan tipe event tersebut. Event yang sama tidak membuat delivery ganda
+func (s *WebhookService) HandleEvent(ctx context.Context, event *outbox.Event) error {
+	db := s.DB.WithContext(ctx)
+
+	subscriptions := make([]entity.WebhookSubscription, 0)
+	if err := s.SubscriptionRepository.FindMany(db, &subscriptions, repo.WithEqual("is_active", true)); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for _, subscription := range subscriptions {
+		events := strings.Split(subscription.Events, ",")
+		if !slices.Contains(events, outbox.AllEvents) && !slices.Contains(events, event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			ID:             uuid.NewString(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        body,
+			Status:         entity.WebhookPending,
+			NextAttemptAt:  now,
+		})
+	}
+
+	return s.DeliveryRepository.Upsert(db, deliveries, repo.OnConflict{DoNothing: true})
+}
+
+// DeliverPending mengirim satu batch delivery yang sudah jadwalnya, mengembalikan jumlah yang diproses
+func (s *WebhookService) DeliverPending(ctx context.Context) (int, error) {
+	db := s.DB.WithContext(ctx)
+
+	deliveries, err := s.DeliveryRepository.ClaimPending(db, s.Options.BatchSize, s.Options.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		s.deliver(ctx, &deliveries[i])
+	}
+	return len(deliveries), nil
+}
+
+func (s *WebhookService) deliver(ctx context.Context, delivery *entity.WebhookDelivery) {
+	db := s.DB.WithContext(ctx)
+
+	subscription := new(entity.WebhookSubscription)
+	if err := s.SubscriptionRepository.FindByID(db, subscription, delivery.SubscriptionID); err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to find webhook %s : %+v", delivery.SubscriptionID, err)
+		return
+	}
+	if !subscription.IsActive {
+		// disimpan sebagai dead, bisa dikirim ulang manual setelah subscription diaktifkan lagi
+		updates := map[string]interface{}{"status": entity.WebhookDead, "locked_until": nil, "last_error": "subscription nonaktif"}
+		if err := s.DeliveryRepository.UpdateOne(db, updates, repo.WithEqual("id", delivery.ID)); err != nil {
+			s.Log.WithContext(ctx).Warnf("Failed to update webhook delivery %s : %+v", delivery.ID, err)
+		}
+		return
+	}
+
+	result, err := s.Sender.Send(ctx, &webhook.Message{
+		ID:     delivery.ID,
+		Event:  delivery.EventType,
+		URL:    subscription.URL,
+		Secret: subscription.Secret,
+		Body:   delivery.Payload,
+	})
+
+	now := time.Now()
+	updates := map[string]interface{}{"locked_until": nil, "attempts": delivery.Attempts + 1}
+	if result != nil && result.StatusCode != 0 {
+		updates["last_status_code"] = result.StatusCode
+		updates["last_response"] = result.Body
+	}
+
+	switch {
+	case err == nil:
+		updates["status"] = entity.WebhookDelivered
+		updates["delivered_at"] = now
+		updates["last_error"] = nil
+	case delivery.Attempts+1 >= s.Options.MaxAttempts:
+		updates["status"] = entity.WebhookDead
+		updates["last_error"] = err.Error()
+		s.Log.WithContext(ctx).Errorf("Webhook delivery %s to %s is dead after %d attempts : %+v", delivery.ID, subscription.URL, delivery.Attempts+1, err)
+	default:
+		updates["next_attempt_at"] = now.Add(outbox.Backoff(delivery.Attempts+1, s.Options.RetryDelay, s.Options.MaxRetryDelay))
+		updates["last_error"] = err.Error()
+		s.Log.WithContext(ctx).Warnf("Webhook delivery %s to %s failed, attempt %d : %+v", delivery.ID, subscription.URL, delivery.Attempts+1, err)
+	}
+
+	if err := s.DeliveryRepository.UpdateOne(db, updates, repo.WithEqual("id", delivery.ID)); err != nil {
+		s.Log.WithContext(ctx).Warnf("Failed to update webhook delivery %s : %+v", delivery.ID, err)
+	}
+}
+
+// StartWebhookDispatcher mengirim delivery pending secara berkala di background sampai ctx selesai
+func StartWebhookDispatcher(ctx context.Context, service *WebhookService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for {
+				sent, err := service.DeliverPending(ctx)
+				if err != nil {
+					service.Log.Warnf("Failed to deliver webhooks : %+v", err)
+				}
+				if err != nil || sent < service.Options.BatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
+
+// newWebhookSecret membuat secret acak 32 byte untuk signature HMAC
+func newWebhookSecret() string {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return "whsec_" + hex.EncodeToString(bytes)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"backend/core/outbox"
+	"backend/core/webhook"
+	"backend/web/entity"
+	"backend/web/repository"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var webhookTestTables = []string{
+	`CREATE TABLE webhook_subscriptions (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL,
+		description TEXT,
+		is_active BOOLEAN NOT NULL DEFAULT 1,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE webhook_deliveries (
+		id TEXT PRIMARY KEY,
+		subscription_id TEXT NOT NULL,
+		event_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME NOT NULL,
+		locked_until DATETIME,
+		last_status_code INTEGER,
+		last_response TEXT,
+		last_error TEXT,
+		delivered_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		UNIQUE (subscription_id, event_id)
+	)`,
+}
+
+func newTestWebhookService(t *testing.T, options WebhookOptions) *WebhookService {
+	t.Helper()
+
+	db := newTestDB(t, webhookTestTables...)
+	log := newTestLogger()
+	return NewWebhookService(db, log, nil, webhook.NewSender(5*time.Second), options,
+		repository.NewWebhookSubscriptionRepository(log), repository.NewWebhookDeliveryRepository(log))
+}
+
+// seedDelivery membuat subscription ke url beserta satu delivery pending yang siap dikirim
+func seedDelivery(t *testing.T, s *WebhookService, url string) string {
+	t.Helper()
+
+	subscription := &entity.WebhookSubscription{ID: "sub-1", URL: url, Secret: "whsec_test", Events: outbox.AllEvents, IsActive: true}
+	if err := s.DB.Create(subscription).Error; err != nil {
+		t.Fatal(err)
+	}
+	delivery := &entity.WebhookDelivery{
+		ID:             "delivery-1",
+		SubscriptionID: subscription.ID,
+		EventID:        "event-1",
+		EventType:      "branch.updated",
+		Payload:        []byte(`{"id":"event-1"}`),
+		Status:         entity.WebhookPending,
+		NextAttemptAt:  time.Now().Add(-time.Second),
+	}
+	if err := s.DB.Create(delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery.ID
+}
+
+func findDelivery(t *testing.T, s *WebhookService, id string) *entity.WebhookDelivery {
+	t.Helper()
+	delivery := new(entity.WebhookDelivery)
+	if err := s.DeliveryRepository.FindByID(s.DB, delivery, id); err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func TestDeliverPendingDelivered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	s := newTestWebhookService(t, WebhookOptions{BatchSize: 10, MaxAttempts: 3, RetryDelay: time.Minute, MaxRetryDelay: time.Hour, Lease: time.Minute})
+	id := seedDelivery(t, s, server.URL)
+
+	sent, err := s.DeliverPending(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("DeliverPending = %d, %v; want 1", sent, err)
+	}
+
+	delivery := findDelivery(t, s, id)
+	if delivery.Status != entity.WebhookDelivered || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+		t.Fatalf("delivery %+v, want delivered after 1 attempt", delivery)
+	}
+	if delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusOK || delivery.LockedUntil != nil {
+		t.Fatalf("delivery %+v, want status 200 and unlocked", delivery)
+	}
+}
+
+func TestDeliverPendingRetryThenDead(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	options := WebhookOptions{BatchSize: 10, MaxAttempts: 3, RetryDelay: time.Minute, MaxRetryDelay: 90 * time.Second, Lease: time.Minute}
+	s := newTestWebhookService(t, options)
+	id := seedDelivery(t, s, server.URL)
+	ctx := context.Background()
+
+	// backoff eksponensial dibatasi MaxRetryDelay: 1m lalu 1m30s (bukan 2m)
+	wantBackoff := []time.Duration{time.Minute, 90 * time.Second}
+	for attempt, backoff := range wantBackoff {
+		before := time.Now()
+		if sent, err := s.DeliverPending(ctx); err != nil || sent != 1 {
+			t.Fatalf("attempt %d: DeliverPending = %d, %v; want 1", attempt+1, sent, err)
+		}
+
+		delivery := findDelivery(t, s, id)
+		if delivery.Status != entity.WebhookPending || delivery.Attempts != attempt+1 {
+			t.Fatalf("attempt %d: delivery %+v, want pending", attempt+1, delivery)
+		}
+		if delivery.LastError == nil || delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("attempt %d: delivery %+v, want last error and status 503", attempt+1, delivery)
+		}
+		if delay := delivery.NextAttemptAt.Sub(before); delay < backoff || delay > backoff+5*time.Second {
+			t.Fatalf("attempt %d: next attempt in %s, want about %s", attempt+1, delay, backoff)
+		}
+
+		// belum jadwalnya, tidak diambil lagi
+		if sent, err := s.DeliverPending(ctx); err != nil || sent != 0 {
+			t.Fatalf("attempt %d: DeliverPending before schedule = %d, %v; want 0", attempt+1, sent, err)
+		}
+		if err := s.DB.Model(&entity.WebhookDelivery{}).Where("id = ?", id).Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if sent, err := s.DeliverPending(ctx); err != nil || sent != 1 {
+		t.Fatalf("last attempt: DeliverPending = %d, %v; want 1", sent, err)
+	}
+	delivery := findDelivery(t, s, id)
+	if delivery.Status != entity.WebhookDead || delivery.Attempts != options.MaxAttempts || delivery.LastError == nil {
+		t.Fatalf("delivery %+v, want dead after %d attempts", delivery, options.MaxAttempts)
+	}
+
+	// delivery dead tidak dikirim lagi sampai redeliver
+	if sent, err := s.DeliverPending(ctx); err != nil || sent != 0 {
+		t.Fatalf("after dead: DeliverPending = %d, %v; want 0", sent, err)
+	}
+	if hits.Load() != int32(options.MaxAttempts) {
+		t.Fatalf("receiver got %d requests, want %d", hits.Load(), options.MaxAttempts)
+	}
+
+	if _, err := s.Redeliver(ctx, "sub-1", id); err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	delivery = findDelivery(t, s, id)
+	if delivery.Status != entity.WebhookPending || delivery.Attempts != 0 {
+		t.Fatalf("after redeliver %+v, want pending with 0 attempts", delivery)
+	}
+}